@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/csimplestring/go-json-schema/schema"
+)
+
+func runBundle(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: jsonschema bundle [flags] schema.json\n\n"+
+			"Writes the schema with every schema it references embedded in its\n"+
+			"$defs, or definitions for the drafts 4 to 7.\n\n")
+		fs.PrintDefaults()
+	}
+
+	var refDirs stringList
+	fs.Var(&refDirs, "ref-dir", "a directory of schema files the $refs can reference, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	opts, err := refDirOptions(refDirs)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema bundle: %s\n", err)
+		return exitError
+	}
+	opts = append(opts, loadRefs...)
+	uri, err := fileURI(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema bundle: %s\n", err)
+		return exitError
+	}
+
+	bundle, err := schema.Bundle(uri, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema bundle: %s\n", err)
+		return exitError
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(bundle); err != nil {
+		fmt.Fprintf(stderr, "jsonschema bundle: %s\n", err)
+		return exitError
+	}
+	return exitValid
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"schema.json":    `{"properties": {"name": {"$ref": "defs/name.json"}, "age": {"$ref": "http://example.com/age.json"}}}`,
+		"defs/name.json": `{"type": "string"}`,
+		"refs/age.json":  `{"$id": "http://example.com/age.json", "type": "integer"}`,
+		"missing.json":   `{"$ref": "defs/missing.json"}`,
+	})
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"bundle", "--ref-dir", filepath.Join(dir, "refs"), filepath.Join(dir, "schema.json")}, nil, &stdout, &stderr)
+	assert.Equal(t, exitValid, status, stderr.String())
+	assert.Equal(t, `{
+  "$defs": {
+    "age": {
+      "$id": "http://example.com/age.json",
+      "type": "integer"
+    },
+    "name": {
+      "$id": "file://DIR/defs/name.json",
+      "type": "string"
+    }
+  },
+  "properties": {
+    "age": {
+      "$ref": "#/$defs/age"
+    },
+    "name": {
+      "$ref": "#/$defs/name"
+    }
+  }
+}
+`, strings.Replace(stdout.String(), filepath.ToSlash(dir), "DIR", -1))
+
+	stdout.Reset()
+	status = run([]string{"bundle", filepath.Join(dir, "missing.json")}, nil, &stdout, &stderr)
+	assert.Equal(t, exitError, status)
+	assert.Empty(t, stdout.String())
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/csimplestring/go-json-schema/schema"
+)
+
+func runDereference(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("dereference", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: jsonschema dereference [flags] schema.json\n\n"+
+			"Writes the schema with its $refs replaced by the schemas they point to.\n"+
+			"The cyclic $refs are kept, unless --no-cyclic-refs is given.\n\n")
+		fs.PrintDefaults()
+	}
+
+	var refDirs stringList
+	fs.Var(&refDirs, "ref-dir", "a directory of schema files the $refs can reference, can be repeated")
+	noCyclicRefs := fs.Bool("no-cyclic-refs", false, "fail on the cyclic $refs instead of keeping them")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	opts, err := refDirOptions(refDirs)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema dereference: %s\n", err)
+		return exitError
+	}
+	opts = append(opts, loadRefs...)
+	if *noCyclicRefs {
+		opts = append(opts, schema.WithoutCyclicRefs())
+	}
+
+	s, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema dereference: %s\n", err)
+		return exitError
+	}
+	// the relative $refs are resolved from the directory of the schema, which
+	// is referenced under its file URI rather than given an $id
+	if _, ok := s.ID(); !ok {
+		uri, err := fileURI(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(stderr, "jsonschema dereference: %s\n", err)
+			return exitError
+		}
+		opts = append(opts, schema.WithSchema(uri, s))
+		s = schema.Schema{"$ref": uri}
+	}
+
+	deref, err := schema.Dereference(s, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema dereference: %s\n", err)
+		return exitError
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(deref); err != nil {
+		fmt.Fprintf(stderr, "jsonschema dereference: %s\n", err)
+		return exitError
+	}
+	return exitValid
+}
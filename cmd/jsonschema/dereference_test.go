@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDereference(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"schema.json":    `{"properties": {"name": {"$ref": "defs/name.json"}, "self": {"$ref": "#"}}}`,
+		"defs/name.json": `{"type": "string"}`,
+	})
+
+	tests := []struct {
+		args   []string
+		status int
+		stdout string
+	}{
+		{
+			args:   []string{filepath.Join(dir, "schema.json")},
+			status: exitValid,
+			stdout: `{
+  "properties": {
+    "name": {
+      "type": "string"
+    },
+    "self": {
+      "$ref": "file://DIR/schema.json"
+    }
+  }
+}
+`,
+		},
+		{
+			args:   []string{"--no-cyclic-refs", filepath.Join(dir, "schema.json")},
+			status: exitError,
+		},
+		{
+			args:   []string{},
+			status: exitError,
+		},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := run(append([]string{"dereference"}, test.args...), nil, &stdout, &stderr)
+		assert.Equal(t, test.status, status, "%v: %s", test.args, stderr.String())
+		assert.Equal(t, test.stdout, strings.Replace(stdout.String(), filepath.ToSlash(dir), "DIR", -1), "%v", test.args)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/csimplestring/go-json-schema/schema"
+)
+
+// The compatibilities the diff command can require, besides the ones of schema.
+const (
+	requireAny  = ""
+	requireFull = "full"
+)
+
+func runDiff(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: jsonschema diff [flags] old.json new.json\n\n"+
+			"Reports the changes of the values the schema accepts from old.json to\n"+
+			"new.json, as backward or forward compatible, or breaking. Exits with 0 if\n"+
+			"the changes have the required compatibility, 1 if one has not, and 2 on\n"+
+			"any other error.\n\n")
+		fs.PrintDefaults()
+	}
+
+	var output, require string
+	var refDirs stringList
+	fs.Var(&refDirs, "ref-dir", "a directory of schema files the $refs can reference, can be repeated")
+	fs.StringVar(&require, "require", requireAny, "the required compatibility: backward, forward or full, none but breaking changes by default")
+	fs.StringVar(&output, "o", outputText, "the output format: text or json")
+	fs.StringVar(&output, "output", outputText, "the output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitError
+	}
+
+	if output != outputText && output != outputJSON {
+		fmt.Fprintf(stderr, "jsonschema diff: unknown output format %s\n", output)
+		return exitError
+	}
+	switch require {
+	case requireAny, requireFull, string(schema.BackwardCompatible), string(schema.ForwardCompatible):
+	default:
+		fmt.Fprintf(stderr, "jsonschema diff: unknown compatibility %s\n", require)
+		return exitError
+	}
+
+	opts, err := refDirOptions(refDirs)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema diff: %s\n", err)
+		return exitError
+	}
+	opts = append(opts, loadRefs...)
+	old, err := loadRootSchema(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema diff: %s\n", err)
+		return exitError
+	}
+	new, err := loadRootSchema(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema diff: %s\n", err)
+		return exitError
+	}
+
+	changes := schema.Compare(old, new, opts...)
+
+	status := exitValid
+	for _, change := range changes {
+		if !compatible(change, require) {
+			status = exitInvalid
+		}
+	}
+
+	if output == outputJSON {
+		type jsonChange struct {
+			Kind          string `json:"kind"`
+			Compatibility string `json:"compatibility"`
+			Pointer       string `json:"pointer"`
+			Keyword       string `json:"keyword"`
+			Message       string `json:"message"`
+		}
+		all := []jsonChange{}
+		for _, change := range changes {
+			all = append(all, jsonChange{string(change.Kind), string(change.Compatibility), "#" + change.Pointer, change.Keyword, change.Message})
+		}
+		json.NewEncoder(stdout).Encode(struct {
+			Changes []jsonChange `json:"changes"`
+		}{all})
+		return status
+	}
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change)
+	}
+	return status
+}
+
+// compatible reports whether change has the required compatibility.
+func compatible(change schema.Change, require string) bool {
+	switch require {
+	case requireAny:
+		return change.Compatibility != schema.Breaking
+	case requireFull:
+		return false
+	default:
+		return string(change.Compatibility) == require
+	}
+}
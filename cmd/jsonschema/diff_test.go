@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"v1.json":        `{"properties": {"name": {"$ref": "defs/name.json"}}}`,
+		"v2.json":        `{"properties": {"name": {"$ref": "defs/name.json", "maxLength": 3}}, "required": ["name"]}`,
+		"v3.json":        `{"properties": {"name": {"type": "integer"}}}`,
+		"defs/name.json": `{"type": "string", "maxLength": 5}`,
+	})
+	v1, v2, v3 := filepath.Join(dir, "v1.json"), filepath.Join(dir, "v2.json"), filepath.Join(dir, "v3.json")
+
+	tests := []struct {
+		args   []string
+		status int
+		stdout string
+	}{
+		{
+			args:   []string{"--require", "full", v1, v1},
+			status: exitValid,
+		},
+		{
+			// the keywords next to a $ref are ignored
+			args:   []string{v1, v2},
+			status: exitValid,
+			stdout: "#: property name is now required (forward)\n",
+		},
+		{
+			args:   []string{"--require", "backward", v1, v2},
+			status: exitInvalid,
+			stdout: "#: property name is now required (forward)\n",
+		},
+		{
+			args:   []string{"-o", "json", v1, v3},
+			status: exitInvalid,
+			stdout: `{"changes":[{"kind":"type","compatibility":"breaking","pointer":"#/properties/name","keyword":"type","message":"type changed from \"string\" to \"integer\""},` +
+				`{"kind":"constraint","compatibility":"backward","pointer":"#/properties/name","keyword":"maxLength","message":"maxLength 5 removed"}]}` + "\n",
+		},
+		{
+			args:   []string{"--require", "sideways", v1, v2},
+			status: exitError,
+		},
+		{
+			args:   []string{v1},
+			status: exitError,
+		},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := run(append([]string{"diff"}, test.args...), nil, &stdout, &stderr)
+		assert.Equal(t, test.status, status, "%v: %s", test.args, stderr.String())
+		assert.Equal(t, test.stdout, strings.Replace(stdout.String(), dir, "DIR", -1), "%v", test.args)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/csimplestring/go-json-schema/schema"
+)
+
+func runGenerate(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: jsonschema generate [flags] schema.json\n\n"+
+			"Writes random documents valid against the schema, one per line, or\n"+
+			"invalid ones with --invalid.\n\n")
+		fs.PrintDefaults()
+	}
+
+	var refDirs stringList
+	fs.Var(&refDirs, "ref-dir", "a directory of schema files the $refs can reference, can be repeated")
+	n := fs.Int("n", 1, "the number of documents")
+	seed := fs.Int64("seed", 0, "the seed of the random documents, the current time by default")
+	invalid := fs.Bool("invalid", false, "generate documents breaking one keyword of the schema")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	opts, err := refDirOptions(refDirs)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema generate: %s\n", err)
+		return exitError
+	}
+	opts = append(opts, loadRefs...)
+	s, err := loadRootSchema(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema generate: %s\n", err)
+		return exitError
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	src := rand.NewSource(*seed)
+	generate := schema.Generate
+	if *invalid {
+		generate = schema.GenerateInvalid
+	}
+
+	enc := json.NewEncoder(stdout)
+	for i := 0; i < *n; i++ {
+		v, err := generate(s, src, opts...)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsonschema generate: %s\n", err)
+			return exitError
+		}
+		if err := enc.Encode(v); err != nil {
+			fmt.Fprintf(stderr, "jsonschema generate: %s\n", err)
+			return exitError
+		}
+	}
+	return exitValid
+}
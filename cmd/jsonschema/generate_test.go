@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"schema.json":  `{"type": "object", "properties": {"id": {"type": "integer", "minimum": 1}, "tag": {"$ref": "tag.json"}}, "required": ["id", "tag"]}`,
+		"tag.json":     `{"type": "string", "enum": ["a", "b"]}`,
+		"pattern.json": `{"type": "string", "pattern": "\\bword"}`,
+	})
+	path := filepath.Join(dir, "schema.json")
+	validator, err := compileSchema(path, nil, loadRefs...)
+	assert.NoError(t, err)
+
+	for _, invalid := range []bool{false, true} {
+		args := []string{"generate", "-n", "5", "--seed", "42"}
+		if invalid {
+			args = append(args, "--invalid")
+		}
+		args = append(args, path)
+
+		var stdout, stderr bytes.Buffer
+		status := run(args, nil, &stdout, &stderr)
+		assert.Equal(t, exitValid, status, stderr.String())
+
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		assert.Len(t, lines, 5)
+		for _, line := range lines {
+			dec := json.NewDecoder(strings.NewReader(line))
+			dec.UseNumber()
+			var v interface{}
+			assert.NoError(t, dec.Decode(&v))
+			assert.Equal(t, !invalid, validator.Validate(v).Valid(), line)
+		}
+
+		// the same seed gives the same documents
+		var again bytes.Buffer
+		run(args, nil, &again, &stderr)
+		assert.Equal(t, stdout.String(), again.String())
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"generate", filepath.Join(dir, "pattern.json")}, nil, &stdout, &stderr)
+	assert.Equal(t, exitError, status)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), `\b is not supported`)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/csimplestring/go-json-schema/schema"
+)
+
+func runLint(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: jsonschema lint [flags] schema.json...\n\n"+
+			"Reports the likely mistakes of the schemas. Exits with 0 if there are\n"+
+			"none, 1 if there are, and 2 on any other error.\n\n"+
+			"rules: %s\n\n", strings.Join(ruleNames(schema.LintRules), ", "))
+		fs.PrintDefaults()
+	}
+
+	var rules, disabled, output string
+	var refDirs stringList
+	fs.StringVar(&rules, "rules", "", "the comma-separated rules to check, all by default")
+	fs.StringVar(&disabled, "disable", "", "the comma-separated rules not to check")
+	fs.Var(&refDirs, "ref-dir", "a directory of schema files the $refs can reference, can be repeated")
+	fs.StringVar(&output, "o", outputText, "the output format: text or json")
+	fs.StringVar(&output, "output", outputText, "the output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if output != outputText && output != outputJSON {
+		fmt.Fprintf(stderr, "jsonschema lint: unknown output format %s\n", output)
+		return exitError
+	}
+
+	opts, err := refDirOptions(refDirs)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema lint: %s\n", err)
+		return exitError
+	}
+	opts = append(opts, loadRefs...)
+	if rules != "" {
+		selected, err := parseRules(rules)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsonschema lint: %s\n", err)
+			return exitError
+		}
+		opts = append(opts, schema.WithLintRules(selected...))
+	}
+	if disabled != "" {
+		skipped, err := parseRules(disabled)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsonschema lint: %s\n", err)
+			return exitError
+		}
+		opts = append(opts, schema.WithoutLintRules(skipped...))
+	}
+
+	files, err := expandFiles(fs.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema lint: %s\n", err)
+		return exitError
+	}
+
+	status := exitValid
+	enc := json.NewEncoder(stdout)
+	for _, name := range files {
+		s, err := readSchema(name, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsonschema lint: %s\n", err)
+			return exitError
+		}
+
+		issues := schema.Lint(s, opts...)
+		if len(issues) > 0 {
+			status = exitInvalid
+		}
+
+		if output == outputJSON {
+			type jsonIssue struct {
+				Rule    string `json:"rule"`
+				Pointer string `json:"pointer"`
+				Message string `json:"message"`
+			}
+			all := []jsonIssue{}
+			for _, issue := range issues {
+				all = append(all, jsonIssue{string(issue.Rule), "#" + issue.Pointer, issue.Message})
+			}
+			enc.Encode(struct {
+				File   string      `json:"file"`
+				Issues []jsonIssue `json:"issues"`
+			}{name, all})
+			continue
+		}
+		for _, issue := range issues {
+			fmt.Fprintf(stdout, "%s%s\n", name, issue)
+		}
+	}
+	return status
+}
+
+// parseRules parses comma-separated rule names.
+func parseRules(names string) ([]schema.LintRule, error) {
+	var rules []schema.LintRule
+	for _, name := range strings.Split(names, ",") {
+		rule := schema.LintRule(strings.TrimSpace(name))
+		known := false
+		for _, one := range schema.LintRules {
+			known = known || one == rule
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown rule %s", rule)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func ruleNames(rules []schema.LintRule) []string {
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, string(rule))
+	}
+	return names
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"clean.json": `{"type": "object", "additionalProperties": false, "properties": {"a": {"type": "string"}}}`,
+		"typo.json":  `{"type": "string", "maxLenght": 3, "minimum": 1}`,
+	})
+
+	tests := []struct {
+		args   []string
+		stdin  string
+		status int
+		stdout string
+	}{
+		{
+			args:   []string{filepath.Join(dir, "clean.json")},
+			status: exitValid,
+		},
+		{
+			args:   []string{filepath.Join(dir, "*.json")},
+			status: exitInvalid,
+			stdout: "DIR/typo.json#: unknown keyword maxLenght, did you mean maxLength? (unknown-keyword)\n" +
+				"DIR/typo.json#: minimum only applies to number values, not [string] (type-keyword)\n",
+		},
+		{
+			args:   []string{"--disable", "type-keyword", "-o", "json", "-"},
+			stdin:  `{"type": "string", "maxLenght": 3, "minimum": 1}`,
+			status: exitInvalid,
+			stdout: `{"file":"-","issues":[{"rule":"unknown-keyword","pointer":"#","message":"unknown keyword maxLenght, did you mean maxLength?"}]}` + "\n",
+		},
+		{
+			args:   []string{"--rules", "min-max", filepath.Join(dir, "typo.json")},
+			status: exitValid,
+		},
+		{
+			args:   []string{"--rules", "typo", filepath.Join(dir, "typo.json")},
+			status: exitError,
+		},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := run(append([]string{"lint"}, test.args...), strings.NewReader(test.stdin), &stdout, &stderr)
+		assert.Equal(t, test.status, status, "%v: %s", test.args, stderr.String())
+		assert.Equal(t, test.stdout, strings.Replace(stdout.String(), dir, "DIR", -1), "%v", test.args)
+	}
+}
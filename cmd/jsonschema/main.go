@@ -0,0 +1,251 @@
+// Command jsonschema validates JSON and YAML documents against a JSON schema.
+//
+// Usage:
+//
+//	jsonschema validate -s schema.json [flags] [files...]
+//	jsonschema lint [flags] schema.json...
+//	jsonschema bundle [flags] schema.json
+//	jsonschema dereference [flags] schema.json
+//	jsonschema diff [flags] old.json new.json
+//	jsonschema generate [flags] schema.json
+//
+// Run a command with -h for its flags.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/csimplestring/go-json-schema/schema"
+)
+
+// The exit codes of the commands.
+const (
+	exitValid   = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+// command runs a command with its arguments, and returns its exit code.
+type command func(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int
+
+var commands = map[string]command{
+	"bundle":      runBundle,
+	"dereference": runDereference,
+	"diff":        runDiff,
+	"generate":    runGenerate,
+	"lint":        runLint,
+	"validate":    runValidate,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "jsonschema: unknown command %s\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+	return cmd(args[1:], stdin, stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "usage: jsonschema <command> [flags] [files...]\n\ncommands: %s\n", strings.Join(names, ", "))
+}
+
+// stringList is a flag that can be given several times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// loadSchema reads the schema in the file at path.
+func loadSchema(path string) (schema.Schema, error) {
+	return readSchema(path, nil)
+}
+
+// readSchema reads the schema in the file named name, or in stdin for "-", as
+// YAML if the name ends with .yaml or .yml, and as JSON otherwise.
+func readSchema(name string, stdin io.Reader) (schema.Schema, error) {
+	f, err := openFile(name, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if isYAML(name) {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		v, _, err := schema.DecodeYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		s, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: the schema is not an object", name)
+		}
+		return schema.Schema(s), nil
+	}
+
+	var s map[string]interface{}
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return schema.Schema(s), nil
+}
+
+// isYAML reports whether the file named name is read as YAML, from its
+// extension.
+func isYAML(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// fileURI returns the file URI of the file at path.
+func fileURI(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return "file://" + filepath.ToSlash(abs), nil
+}
+
+// loadRefs allows the $refs to load schemas from the network and the file
+// system, which every command does unless validate is given --no-load-refs.
+var loadRefs = []schema.Option{schema.WithRemoteRefs(), schema.WithFileRefs()}
+
+// compileSchema compiles the schema at path, see loadRootSchema, with the
+// schemas of refDirs, see refDirOptions.
+func compileSchema(path string, refDirs []string, opts ...schema.Option) (*schema.Validator, error) {
+	dirOpts, err := refDirOptions(refDirs)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, dirOpts...)
+
+	s, err := loadRootSchema(path)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Compile(s, opts...)
+}
+
+// loadRootSchema reads the schema at path, and gives it its file URI as $id if
+// it has none, so that its relative $refs are resolved from its directory.
+func loadRootSchema(path string) (schema.Schema, error) {
+	s, err := loadSchema(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.ID(); !ok {
+		uri, err := fileURI(path)
+		if err != nil {
+			return nil, err
+		}
+		s["$id"] = uri
+	}
+	return s, nil
+}
+
+// refDirOptions returns the options making the schemas of the JSON and YAML
+// files in refDirs available to the $refs, under their $id resolved against
+// their file URI.
+func refDirOptions(refDirs []string) ([]schema.Option, error) {
+	var opts []schema.Option
+	for _, dir := range refDirs {
+		var files []string
+		for _, pattern := range []string{"*.json", "*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+		for _, file := range files {
+			s, err := loadSchema(file)
+			if err != nil {
+				return nil, err
+			}
+			uri, err := fileURI(file)
+			if err != nil {
+				return nil, err
+			}
+			if id, ok := s.ID(); ok {
+				base, _ := url.Parse(uri)
+				ref, err := url.Parse(id)
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid $id %s", file, id)
+				}
+				resolved := base.ResolveReference(ref)
+				resolved.Fragment = ""
+				uri = resolved.String()
+			}
+			opts = append(opts, schema.WithSchema(uri, s))
+		}
+	}
+	return opts, nil
+}
+
+// expandFiles expands the glob patterns of args. "-" stands for the standard
+// input, as no args at all.
+func expandFiles(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"-"}, nil
+	}
+
+	var files []string
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			files = append(files, arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no file matches %s", arg)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// openFile opens the file named name, or returns stdin for "-".
+func openFile(name string, stdin io.Reader) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(name)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/csimplestring/go-json-schema/schema"
+)
+
+// The output formats of the validate command. flag and basic are the output
+// formats of the JSON Schema specification, with one document per line.
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputFlag  = "flag"
+	outputBasic = "basic"
+)
+
+func runValidate(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: jsonschema validate -s schema.json [flags] [files...]\n\n"+
+			"Validates the files, or the standard input if there are none or for -.\n"+
+			"The files ending with .yaml or .yml are read as YAML, the others as JSON.\n"+
+			"Exits with 0 if every document is valid, 1 if one is not, and 2 on\n"+
+			"any other error, such as an invalid schema.\n\n")
+		fs.PrintDefaults()
+	}
+
+	var schemaPath, output string
+	var refDirs stringList
+	fs.StringVar(&schemaPath, "s", "", "the schema file")
+	fs.StringVar(&schemaPath, "schema", "", "the schema file")
+	fs.Var(&refDirs, "ref-dir", "a directory of schema files the $refs can reference, can be repeated")
+	fs.StringVar(&output, "o", outputText, "the output format: text, json, flag or basic")
+	fs.StringVar(&output, "output", outputText, "the output format: text, json, flag or basic")
+	ndjson := fs.Bool("ndjson", false, "validate every line of the files as a JSON document")
+	noLoad := fs.Bool("no-load-refs", false, "forbid the $refs to load schemas from the network or the file system, "+
+		"only the schema itself and the --ref-dir schemas can be referenced")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if schemaPath == "" {
+		fmt.Fprintln(stderr, "jsonschema validate: missing -s schema.json")
+		return exitError
+	}
+	w, err := newResultWriter(output, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema validate: %s\n", err)
+		return exitError
+	}
+
+	var opts []schema.Option
+	if !*noLoad {
+		opts = append(opts, loadRefs...)
+	}
+	validator, err := compileSchema(schemaPath, refDirs, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema validate: %s\n", err)
+		return exitError
+	}
+
+	files, err := expandFiles(fs.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "jsonschema validate: %s\n", err)
+		return exitError
+	}
+
+	status := exitValid
+	for _, name := range files {
+		f, err := openFile(name, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "jsonschema validate: %s\n", err)
+			return exitError
+		}
+
+		if *ndjson {
+			err = validator.ValidateLines(f, func(line int, res *schema.Result) {
+				if !res.Valid() {
+					status = exitInvalid
+				}
+				w.write(name+":"+strconv.Itoa(line), res, nil)
+			})
+		} else {
+			var res *schema.Result
+			if isYAML(name) {
+				var data []byte
+				if data, err = io.ReadAll(f); err != nil {
+					f.Close()
+					fmt.Fprintf(stderr, "jsonschema validate: %s: %s\n", name, err)
+					return exitError
+				}
+				res, err = validator.ValidateYAML(data)
+			} else {
+				res, err = validator.ValidateReader(f)
+			}
+			if err != nil || !res.Valid() {
+				status = exitInvalid
+			}
+			w.write(name, res, err)
+			// invalid JSON or YAML is reported as a result
+			err = nil
+		}
+		f.Close()
+
+		if err != nil {
+			fmt.Fprintf(stderr, "jsonschema validate: %s: %s\n", name, err)
+			return exitError
+		}
+	}
+	return status
+}
+
+// resultWriter writes the results of the validations in an output format.
+type resultWriter struct {
+	format string
+	w      io.Writer
+	enc    *json.Encoder
+}
+
+func newResultWriter(format string, w io.Writer) (*resultWriter, error) {
+	switch format {
+	case outputText, outputJSON, outputFlag, outputBasic:
+		return &resultWriter{format: format, w: w, enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %s", format)
+	}
+}
+
+type jsonError struct {
+	Path    string `json:"path"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Line and Column are the position of the value in a YAML document.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+}
+
+type basicError struct {
+	KeywordLocation  string `json:"keywordLocation"`
+	InstanceLocation string `json:"instanceLocation"`
+	Error            string `json:"error"`
+}
+
+// write writes the result of the document name, or the error telling it is not
+// valid JSON, or YAML.
+func (rw *resultWriter) write(name string, res *schema.Result, invalidJSON error) {
+	valid := invalidJSON == nil && res.Valid()
+	kind := "JSON"
+	if isYAML(name) {
+		kind = "YAML"
+	}
+
+	switch rw.format {
+	case outputText:
+		if invalidJSON != nil {
+			fmt.Fprintf(rw.w, "%s: invalid %s: %s\n", name, kind, invalidJSON)
+			return
+		}
+		if valid {
+			fmt.Fprintf(rw.w, "%s: valid\n", name)
+			return
+		}
+		for i, msg := range res.Messages() {
+			// the messages of the YAML documents start with their position
+			if _, ok := res.Position(res.Errors[i]); ok {
+				fmt.Fprintf(rw.w, "%s:%s\n", name, msg)
+				continue
+			}
+			fmt.Fprintf(rw.w, "%s: %s\n", name, msg)
+		}
+
+	case outputJSON:
+		errs := []jsonError{}
+		if invalidJSON != nil {
+			errs = append(errs, jsonError{Path: "$", Code: string(schema.InvalidJSONError), Message: invalidJSON.Error()})
+		} else {
+			for _, e := range res.Errors {
+				pos, _ := res.Position(e)
+				errs = append(errs, jsonError{Path: e.Path(), Code: string(e.Code()), Message: res.Format(e), Line: pos.Line, Column: pos.Column})
+			}
+		}
+		rw.enc.Encode(struct {
+			File   string      `json:"file"`
+			Valid  bool        `json:"valid"`
+			Errors []jsonError `json:"errors"`
+		}{name, valid, errs})
+
+	case outputFlag:
+		rw.enc.Encode(struct {
+			Valid bool `json:"valid"`
+		}{valid})
+
+	case outputBasic:
+		var errs []basicError
+		if invalidJSON != nil {
+			errs = append(errs, basicError{InstanceLocation: "", Error: "invalid " + kind + ": " + invalidJSON.Error()})
+		} else {
+			for _, e := range res.Errors {
+				errs = append(errs, basicError{
+					KeywordLocation:  res.KeywordLocation(e),
+					InstanceLocation: instanceLocation(e.Path()),
+					Error:            res.Format(e),
+				})
+			}
+		}
+		rw.enc.Encode(struct {
+			Valid  bool         `json:"valid"`
+			Errors []basicError `json:"errors,omitempty"`
+		}{valid, errs})
+	}
+}
+
+// instanceLocation converts the path of an error, such as $.a[0], to a JSON
+// pointer, such as /a/0. The property names containing . or [ can not be told
+// apart in the paths, they are split.
+func instanceLocation(path string) string {
+	path = strings.TrimPrefix(path, "$")
+
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '[' }) {
+		token := strings.TrimSuffix(part, "]")
+		token = strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+		b.WriteString("/" + token)
+	}
+	return b.String()
+}
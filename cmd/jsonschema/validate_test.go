@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles writes the files to a temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestValidate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"schema.json":       `{"type": "object", "properties": {"name": {"$ref": "defs/name.json"}, "age": {"$ref": "http://example.com/age.json"}}}`,
+		"defs/name.json":    `{"type": "string", "maxLength": 3}`,
+		"refs/age.json":     `{"$id": "http://example.com/age.json", "type": "integer"}`,
+		"data/valid.json":   `{"name": "abc", "age": 3}`,
+		"data/invalid.json": `{"name": "abcd", "age": "3"}`,
+		"data/broken.json":  `{"name": `,
+		"lines.ndjson":      "{\"name\": \"a\"}\n\n{\"name\": 1}\n",
+		"bad-schema.json":   `{"$ref": "#/definitions/missing"}`,
+		"local-schema.json": `{"properties": {"name": {"$ref": "#/definitions/name"}, "age": {"$ref": "http://example.com/age.json"}}, "definitions": {"name": {"type": "string"}}}`,
+	})
+	schemaPath := filepath.Join(dir, "schema.json")
+	refDir := filepath.Join(dir, "refs")
+
+	tests := []struct {
+		args   []string
+		stdin  string
+		status int
+		stdout string
+	}{
+		{
+			args:   []string{"-s", schemaPath, "--ref-dir", refDir, filepath.Join(dir, "data/valid.json")},
+			status: exitValid,
+			stdout: "DIR/data/valid.json: valid\n",
+		},
+		{
+			args:   []string{"-s", schemaPath, "--ref-dir", refDir, filepath.Join(dir, "data/*.json")},
+			status: exitInvalid,
+			stdout: "DIR/data/broken.json: invalid JSON: unexpected EOF\n" +
+				"DIR/data/invalid.json: $.name: must be at most 3 characters long\n" +
+				"DIR/data/invalid.json: $.age: must be of type integer\n" +
+				"DIR/data/valid.json: valid\n",
+		},
+		{
+			args:   []string{"--schema", schemaPath, "--ref-dir", refDir, "-o", "json"},
+			stdin:  `{"name": "abcd"}`,
+			status: exitInvalid,
+			stdout: `{"file":"-","valid":false,"errors":[{"path":"$.name","code":"maxLength","message":"must be at most 3 characters long"}]}` + "\n",
+		},
+		{
+			args:   []string{"-s", schemaPath, "--ref-dir", refDir, "-o", "flag", "-"},
+			stdin:  `{"name": "abc"}`,
+			status: exitValid,
+			stdout: `{"valid":true}` + "\n",
+		},
+		{
+			args:   []string{"-s", schemaPath, "--ref-dir", refDir, "-o", "basic", "-"},
+			stdin:  `{"name": "abcd"}`,
+			status: exitInvalid,
+			stdout: `{"valid":false,"errors":[{"keywordLocation":"/properties/name/$ref/maxLength","instanceLocation":"/name","error":"must be at most 3 characters long"}]}` + "\n",
+		},
+		{
+			args:   []string{"-s", schemaPath, "--ref-dir", refDir, "-"},
+			stdin:  `{"name": "abc"} ]]] garbage`,
+			status: exitInvalid,
+			stdout: "-: invalid JSON: invalid character ']' looking for beginning of value\n",
+		},
+		{
+			args:   []string{"-s", schemaPath, "--ref-dir", refDir, "-"},
+			stdin:  `{"name": "abc"} {"name": "abcd"}`,
+			status: exitInvalid,
+			stdout: "-: invalid JSON: unexpected data after the document\n",
+		},
+		{
+			args:   []string{"-s", schemaPath, "--ref-dir", refDir, "--ndjson", filepath.Join(dir, "lines.ndjson")},
+			status: exitInvalid,
+			stdout: "DIR/lines.ndjson:1: valid\nDIR/lines.ndjson:3: $.name: must be of type string\n",
+		},
+		{
+			args:   []string{"-s", filepath.Join(dir, "bad-schema.json"), filepath.Join(dir, "data/valid.json")},
+			status: exitError,
+		},
+		{
+			args:   []string{"-s", schemaPath, filepath.Join(dir, "data/valid.json")},
+			status: exitError,
+		},
+		{
+			args:   []string{"-s", schemaPath, "--ref-dir", refDir, "--no-load-refs", filepath.Join(dir, "data/valid.json")},
+			status: exitError,
+		},
+		{
+			args:   []string{"-s", filepath.Join(dir, "local-schema.json"), "--ref-dir", refDir, "--no-load-refs", filepath.Join(dir, "data/valid.json")},
+			status: exitValid,
+			stdout: "DIR/data/valid.json: valid\n",
+		},
+		{
+			args:   []string{"-s", schemaPath, "-o", "xml"},
+			status: exitError,
+		},
+		{
+			args:   []string{filepath.Join(dir, "data/valid.json")},
+			status: exitError,
+		},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := run(append([]string{"validate"}, test.args...), strings.NewReader(test.stdin), &stdout, &stderr)
+		assert.Equal(t, test.status, status, "%v: %s", test.args, stderr.String())
+		assert.Equal(t, test.stdout, strings.Replace(stdout.String(), dir, "DIR", -1), "%v", test.args)
+	}
+}
+
+func TestValidateYAML(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"schema.yaml":       "type: object\nproperties:\n  name: {$ref: defs/name.yaml}\n  age: {$ref: \"http://example.com/age.json\"}\n",
+		"defs/name.yaml":    "type: string\nmaxLength: 3\n",
+		"refs/age.yml":      "$id: http://example.com/age.json\ntype: integer\n",
+		"data/valid.yaml":   "name: abc\nage: 3\n",
+		"data/invalid.yaml": "name: abcd\nage: \"3\"\n",
+		"data/broken.yml":   "name: [\n",
+	})
+	schemaPath := filepath.Join(dir, "schema.yaml")
+	refDir := filepath.Join(dir, "refs")
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"validate", "-s", schemaPath, "--ref-dir", refDir, filepath.Join(dir, "data/*")}, nil, &stdout, &stderr)
+	assert.Equal(t, exitInvalid, status, stderr.String())
+	assert.Equal(t, "DIR/data/broken.yml: invalid YAML: YAML Error: yaml: line 1: did not find expected node content\n"+
+		"DIR/data/invalid.yaml:2:1: $.age: must be of type integer\n"+
+		"DIR/data/invalid.yaml:1:1: $.name: must be at most 3 characters long\n"+
+		"DIR/data/valid.yaml: valid\n", strings.Replace(stdout.String(), dir, "DIR", -1))
+
+	stdout.Reset()
+	status = run([]string{"validate", "-s", schemaPath, "--ref-dir", refDir, "-o", "json", filepath.Join(dir, "data/invalid.yaml")}, nil, &stdout, &stderr)
+	assert.Equal(t, exitInvalid, status, stderr.String())
+	assert.Equal(t, `{"file":"DIR/data/invalid.yaml","valid":false,"errors":[`+
+		`{"path":"$.age","code":"not match type","message":"must be of type integer","line":2,"column":1},`+
+		`{"path":"$.name","code":"maxLength","message":"must be at most 3 characters long","line":1,"column":1}]}`+"\n", strings.Replace(stdout.String(), dir, "DIR", -1))
+}
+
+func TestInstanceLocation(t *testing.T) {
+	assert.Equal(t, "", instanceLocation("$"))
+	assert.Equal(t, "/a/0/b~1c", instanceLocation("$.a[0].b/c"))
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitError, run([]string{"lint2"}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "unknown command lint2")
+}
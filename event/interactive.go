@@ -0,0 +1,36 @@
+package event
+
+import "github.com/csimplestring/go-json-schema/schema"
+
+// DecisionHandler handles the errors of an interactive validation, and decides
+// how the validation goes on. The returned value is only used with
+// schema.Replace.
+type DecisionHandler interface {
+	Decide(arg EventArg) (schema.Decision, interface{})
+}
+
+// DecisionHandlerFunc adapts a function to a DecisionHandler.
+type DecisionHandlerFunc func(arg EventArg) (schema.Decision, interface{})
+
+func (f DecisionHandlerFunc) Decide(arg EventArg) (schema.Decision, interface{}) {
+	return f(arg)
+}
+
+// Interactive returns a schema.Resolver that dispatches every error to h, to be
+// passed to a validation with schema.WithInteractive.
+func Interactive(h DecisionHandler) schema.Resolver {
+	return &resolver{handler: h}
+}
+
+type resolver struct {
+	handler DecisionHandler
+}
+
+func (r *resolver) Resolve(e schema.SchemaError, v interface{}) (schema.Decision, interface{}) {
+	return r.handler.Decide(EventArg{
+		Name:    schema.ErrorEvent,
+		Path:    e.Path(),
+		ErrCode: e.Code(),
+		Value:   v,
+	})
+}
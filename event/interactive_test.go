@@ -0,0 +1,139 @@
+package event
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/csimplestring/go-json-schema/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInteractive(t *testing.T) {
+	s := schema.Schema{
+		"items": map[string]interface{}{
+			"type":      "string",
+			"minLength": json.Number("3"),
+			"pattern":   "^a",
+		},
+	}
+
+	tests := []struct {
+		decision      schema.Decision
+		replacement   interface{}
+		expectedPaths []string
+		expectedValue interface{}
+	}{
+		{
+			decision:      schema.Continue,
+			expectedPaths: []string{"$[0]", "$[0]", "$[1]", "$[1]"},
+			expectedValue: []interface{}{"x", "x"},
+		},
+		{
+			decision:      schema.Abort,
+			expectedPaths: []string{"$[0]"},
+			expectedValue: []interface{}{"x", "x"},
+		},
+		{
+			decision:      schema.Skip,
+			expectedPaths: []string{"$[0]", "$[1]"},
+			expectedValue: []interface{}{"x", "x"},
+		},
+		{
+			decision:      schema.Replace,
+			replacement:   "abc",
+			expectedPaths: nil,
+			expectedValue: []interface{}{"abc", "abc"},
+		},
+		{
+			decision:      schema.Replace,
+			replacement:   "ab",
+			expectedPaths: []string{"$[0]", "$[1]"},
+			expectedValue: []interface{}{"ab", "ab"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		var values []interface{}
+		r := Interactive(DecisionHandlerFunc(func(arg EventArg) (schema.Decision, interface{}) {
+			values = append(values, arg.Value)
+			// the replacement is invalid too, keep it
+			if arg.Value == test.replacement {
+				return schema.Continue, nil
+			}
+			return test.decision, test.replacement
+		}))
+
+		res := schema.NewValidator(s, schema.WithInteractive(r)).Validate([]interface{}{"x", "x"})
+
+		var paths []string
+		for _, e := range res.Errors {
+			paths = append(paths, e.Path())
+		}
+		assert.Equal(t, test.expectedPaths, paths)
+		assert.Equal(t, test.expectedValue, res.Value)
+		assert.Contains(t, values, "x")
+	}
+}
+
+func TestInteractiveReplaceRoot(t *testing.T) {
+	r := Interactive(DecisionHandlerFunc(func(arg EventArg) (schema.Decision, interface{}) {
+		return schema.Replace, "fixed"
+	}))
+
+	res := schema.NewValidator(schema.Schema{"type": "string"}, schema.WithInteractive(r)).Validate(json.Number("1"))
+	assert.True(t, res.Valid())
+	assert.Equal(t, "fixed", res.Value)
+}
+
+func TestInteractiveReplaceInvalid(t *testing.T) {
+	s := schema.Schema{"type": "integer", "maximum": json.Number("3")}
+
+	tests := []struct {
+		replacement   interface{}
+		expectedCodes []schema.ErrorCode
+		expectedValue interface{}
+	}{
+		{
+			// numbers are converted to json.Number
+			replacement:   5,
+			expectedCodes: []schema.ErrorCode{schema.NumericMaximumError},
+			expectedValue: json.Number("5"),
+		},
+		{
+			replacement:   map[string]interface{}{"a": 1.5},
+			expectedCodes: []schema.ErrorCode{schema.TypeNotMatchError},
+			expectedValue: map[string]interface{}{"a": json.Number("1.5")},
+		},
+		{
+			replacement:   make(chan int),
+			expectedCodes: []schema.ErrorCode{schema.InvalidReplacementError},
+			expectedValue: "x",
+		},
+		{
+			// replaced again and again
+			replacement:   "y",
+			expectedCodes: []schema.ErrorCode{schema.MaxReplacementsError},
+			expectedValue: "y",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		r := Interactive(DecisionHandlerFunc(func(arg EventArg) (schema.Decision, interface{}) {
+			if _, ok := arg.Value.(string); ok {
+				return schema.Replace, test.replacement
+			}
+			return schema.Continue, nil
+		}))
+
+		res := schema.NewValidator(s, schema.WithInteractive(r)).Validate("x")
+
+		var codes []schema.ErrorCode
+		for _, e := range res.Errors {
+			codes = append(codes, e.Code())
+		}
+		assert.Equal(t, test.expectedCodes, codes, "%v", test.replacement)
+		assert.Equal(t, test.expectedValue, res.Value, "%v", test.replacement)
+	}
+}
@@ -1,19 +1,189 @@
 package event
 
-import "github.com/csimplestring/go-json-schema/schema"
+import (
+	"sync"
+
+	"github.com/csimplestring/go-json-schema/schema"
+)
+
+// AllEvents subscribes a handler to every event.
+const AllEvents = "*"
 
 type EventArg struct {
+	Name    string
 	Path    string
+	Keyword string
 	ErrCode schema.ErrorCode
+	// Value is only set for the errors of an interactive validation.
+	Value interface{}
 }
 
 type EventHandler interface {
 	Handle(arg EventArg)
 }
 
+// EventHandlerFunc adapts a function to an EventHandler.
+type EventHandlerFunc func(arg EventArg)
+
+func (f EventHandlerFunc) Handle(arg EventArg) {
+	f(arg)
+}
+
+// EventManager dispatches events to the handlers subscribed to them. It
+// implements schema.EventEmitter, so it can be passed to a validation with
+// schema.WithEventEmitter. The zero value is ready to use.
 type EventManager struct {
+	mu       sync.RWMutex
+	handlers map[string][]EventHandler
+	async    []*asyncHandler
+	closed   bool
 }
 
+func NewEventManager() *EventManager {
+	return &EventManager{}
+}
+
+// Subscribe calls h synchronously for every event named eventName, or for every
+// event if eventName is AllEvents. A handler may subscribe other handlers, they
+// are called from the next event on.
+func (em *EventManager) Subscribe(eventName string, h EventHandler) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	em.subscribe(eventName, h)
+}
+
+func (em *EventManager) subscribe(eventName string, h EventHandler) {
+	if em.handlers == nil {
+		em.handlers = make(map[string][]EventHandler)
+	}
+	em.handlers[eventName] = append(em.handlers[eventName], h)
+}
+
+// SubscribeAsync calls h from its own goroutine, through a buffer of size events.
+// Dispatch blocks while the buffer is full, until Close, which drops the events
+// still waiting for room. So an h dispatching events to itself does not block
+// forever. Close must be called to flush the buffer once the validation is done.
+// SubscribeAsync does nothing once the manager is closed, as no event would be
+// dispatched to h.
+func (em *EventManager) SubscribeAsync(eventName string, h EventHandler, size int) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	if em.closed {
+		return
+	}
+
+	a := newAsyncHandler(h, size)
+	em.async = append(em.async, a)
+	em.subscribe(eventName, a)
+}
+
+// Dispatch calls the handlers subscribed to eventName with arg. Events dispatched
+// after Close are dropped. The handlers are called without holding the lock of
+// the manager, so they can subscribe or dispatch themselves.
 func (em *EventManager) Dispatch(eventName string, arg EventArg) {
+	em.mu.RLock()
+	if em.closed {
+		em.mu.RUnlock()
+		return
+	}
+	handlers := make([]EventHandler, 0, len(em.handlers[eventName])+len(em.handlers[AllEvents]))
+	handlers = append(handlers, em.handlers[eventName]...)
+	handlers = append(handlers, em.handlers[AllEvents]...)
+	em.mu.RUnlock()
+
+	arg.Name = eventName
+	for _, h := range handlers {
+		h.Handle(arg)
+	}
+}
+
+// Emit implements schema.EventEmitter.
+func (em *EventManager) Emit(name string, path string, keyword string, code schema.ErrorCode) {
+	em.Dispatch(name, EventArg{
+		Path:    path,
+		Keyword: keyword,
+		ErrCode: code,
+	})
+}
+
+// Close stops dispatching events and waits until the asynchronous handlers have
+// handled the events in their buffer.
+func (em *EventManager) Close() {
+	em.mu.Lock()
+	if em.closed {
+		em.mu.Unlock()
+		return
+	}
+	em.closed = true
+	async := em.async
+	em.mu.Unlock()
+
+	for _, a := range async {
+		a.close()
+	}
+}
+
+type asyncHandler struct {
+	handler EventHandler
+	events  chan EventArg
+	// stop is closed by close. It drops the events dispatched from then on,
+	// including the ones waiting for room in a full buffer.
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newAsyncHandler(h EventHandler, size int) *asyncHandler {
+	a := &asyncHandler{
+		handler: h,
+		events:  make(chan EventArg, size),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(a.done)
+		for {
+			select {
+			case arg := <-a.events:
+				a.handler.Handle(arg)
+			case <-a.stop:
+				a.drain()
+				return
+			}
+		}
+	}()
+
+	return a
+}
+
+// drain handles the events left in the buffer.
+func (a *asyncHandler) drain() {
+	for {
+		select {
+		case arg := <-a.events:
+			a.handler.Handle(arg)
+		default:
+			return
+		}
+	}
+}
+
+func (a *asyncHandler) Handle(arg EventArg) {
+	select {
+	case <-a.stop:
+		return
+	default:
+	}
+
+	select {
+	case a.events <- arg:
+	case <-a.stop:
+	}
+}
 
+func (a *asyncHandler) close() {
+	close(a.stop)
+	<-a.done
 }
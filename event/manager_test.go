@@ -0,0 +1,146 @@
+package event
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/csimplestring/go-json-schema/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventManagerDispatch(t *testing.T) {
+	em := NewEventManager()
+
+	var errors, all []EventArg
+	em.Subscribe(schema.ErrorEvent, EventHandlerFunc(func(arg EventArg) {
+		errors = append(errors, arg)
+	}))
+	em.Subscribe(AllEvents, EventHandlerFunc(func(arg EventArg) {
+		all = append(all, arg)
+	}))
+
+	em.Dispatch(schema.SchemaEnteredEvent, EventArg{Path: "a"})
+	em.Dispatch(schema.ErrorEvent, EventArg{Path: "a", ErrCode: schema.EnumError})
+
+	assert.Equal(t, []EventArg{
+		{Name: schema.ErrorEvent, Path: "a", ErrCode: schema.EnumError},
+	}, errors)
+	assert.Equal(t, []EventArg{
+		{Name: schema.SchemaEnteredEvent, Path: "a"},
+		{Name: schema.ErrorEvent, Path: "a", ErrCode: schema.EnumError},
+	}, all)
+
+	em.Close()
+	em.Dispatch(schema.ErrorEvent, EventArg{Path: "b"})
+	assert.Len(t, errors, 1)
+}
+
+func TestEventManagerAsync(t *testing.T) {
+	var em EventManager
+
+	var mu sync.Mutex
+	count := 0
+	em.SubscribeAsync(schema.KeywordEvaluatedEvent, EventHandlerFunc(func(arg EventArg) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	}), 4)
+
+	for i := 0; i < 100; i++ {
+		em.Dispatch(schema.KeywordEvaluatedEvent, EventArg{Keyword: "type"})
+	}
+	em.Close()
+
+	assert.Equal(t, 100, count)
+}
+
+func TestEventManagerSubscribeFromHandler(t *testing.T) {
+	em := NewEventManager()
+
+	count := 0
+	em.Subscribe(schema.ErrorEvent, EventHandlerFunc(func(arg EventArg) {
+		em.Subscribe(AllEvents, EventHandlerFunc(func(arg EventArg) {
+			count++
+		}))
+	}))
+
+	em.Dispatch(schema.ErrorEvent, EventArg{Path: "a"})
+	assert.Equal(t, 0, count)
+
+	em.Dispatch(schema.SchemaEnteredEvent, EventArg{Path: "a"})
+	assert.Equal(t, 1, count)
+}
+
+func TestEventManagerSubscribeAsyncAfterClose(t *testing.T) {
+	em := NewEventManager()
+	em.Close()
+
+	called := false
+	em.SubscribeAsync(AllEvents, EventHandlerFunc(func(arg EventArg) {
+		called = true
+	}), 1)
+	em.Dispatch(schema.ErrorEvent, EventArg{Path: "a"})
+
+	assert.False(t, called)
+	assert.Empty(t, em.async)
+}
+
+func TestEventManagerAsyncFullBuffer(t *testing.T) {
+	em := NewEventManager()
+
+	var count int32
+	em.SubscribeAsync(schema.ErrorEvent, EventHandlerFunc(func(arg EventArg) {
+		atomic.AddInt32(&count, 1)
+		// the second event waits for room in the buffer this handler empties
+		em.Dispatch(schema.ErrorEvent, arg)
+		em.Dispatch(schema.ErrorEvent, arg)
+	}), 1)
+	em.Dispatch(schema.ErrorEvent, EventArg{Path: "a"})
+
+	closed := make(chan struct{})
+	go func() {
+		em.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on the full buffer")
+	}
+	assert.True(t, atomic.LoadInt32(&count) >= 1)
+
+	// dropped once closed
+	em.Dispatch(schema.ErrorEvent, EventArg{Path: "b"})
+}
+
+func TestEventManagerValidation(t *testing.T) {
+	em := NewEventManager()
+
+	var events []EventArg
+	em.Subscribe(AllEvents, EventHandlerFunc(func(arg EventArg) {
+		events = append(events, arg)
+	}))
+
+	s := schema.Schema{
+		"type": "array",
+		"items": map[string]interface{}{
+			"maximum": json.Number("1"),
+		},
+	}
+	res := schema.NewValidator(s, schema.WithEventEmitter(em)).Validate([]interface{}{json.Number("2")})
+	assert.False(t, res.Valid())
+
+	assert.Equal(t, []EventArg{
+		{Name: schema.SchemaEnteredEvent, Path: "$"},
+		{Name: schema.KeywordEvaluatedEvent, Path: "$", Keyword: "type"},
+		{Name: schema.SchemaEnteredEvent, Path: "$[0]"},
+		{Name: schema.ErrorEvent, Path: "$[0]", Keyword: "maximum", ErrCode: schema.NumericMaximumError},
+		{Name: schema.KeywordEvaluatedEvent, Path: "$[0]", Keyword: "maximum"},
+		{Name: schema.SchemaExitedEvent, Path: "$[0]"},
+		{Name: schema.KeywordEvaluatedEvent, Path: "$", Keyword: "items"},
+		{Name: schema.SchemaExitedEvent, Path: "$"},
+	}, events)
+}
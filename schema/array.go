@@ -3,6 +3,7 @@ package schema
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 )
 
 type ArrayConstraint struct {
@@ -19,16 +20,30 @@ func NewArrayConstraint(schema Schema) *ArrayConstraint {
 func (constraint *ArrayConstraint) Validate(v interface{}, path string) {
 	arr := v.([]interface{})
 
-	constraint.validateMaxItems(arr, path)
-	constraint.validateMinItems(arr, path)
-	constraint.validateUniqueItem(arr, path)
-	constraint.validateItems(arr, path)
+	validations := []struct {
+		keyword  string
+		validate func([]interface{}, string)
+	}{
+		{"maxItems", constraint.validateMaxItems},
+		{"minItems", constraint.validateMinItems},
+		{"uniqueItems", constraint.validateUniqueItem},
+		{"items", constraint.validateItems},
+	}
+	for _, validation := range validations {
+		if constraint.stopped() {
+			return
+		}
+		validation.validate(arr, path)
+		if _, ok := constraint.schema[validation.keyword]; ok {
+			constraint.evaluated(path, validation.keyword)
+		}
+	}
 }
 
 func (constraint *ArrayConstraint) validateMaxItems(items []interface{}, path string) {
 	if max, exist := constraint.schema.MaxItems(); exist {
 		if len(items) > max {
-			constraint.addError(newError(ArrayMaxItemError, path))
+			constraint.addError(newError(ArrayMaxItemError, path).withParam("limit", max))
 		}
 	}
 }
@@ -36,21 +51,23 @@ func (constraint *ArrayConstraint) validateMaxItems(items []interface{}, path st
 func (constraint *ArrayConstraint) validateMinItems(items []interface{}, path string) {
 	if min, exist := constraint.schema.MinItems(); exist {
 		if len(items) < min {
-			constraint.addError(newError(ArrayMinItemError, path))
+			constraint.addError(newError(ArrayMinItemError, path).withParam("limit", min))
 		}
 	}
 }
 
+// validateUniqueItem reports every item that is equal to an item before it.
 func (constraint *ArrayConstraint) validateUniqueItem(items []interface{}, path string) {
-	length := len(items)
-	if length == 0 {
+	if !constraint.schema.UniqueItems() {
 		return
 	}
 
-	one := items[0]
-	for i := 1; i < length; i++ {
-		if !reflect.DeepEqual(one, items[i]) {
-			constraint.addError(newError(ArrayUniqueItemError, path+fmt.Sprintf("[%d]", i)))
+	for i := 1; i < len(items); i++ {
+		for j := 0; j < i; j++ {
+			if reflect.DeepEqual(items[j], items[i]) {
+				constraint.addError(newError(ArrayUniqueItemError, path+fmt.Sprintf("[%d]", i)))
+				break
+			}
 		}
 	}
 }
@@ -62,51 +79,77 @@ func (constraint *ArrayConstraint) validateItems(items []interface{}, path strin
 	}
 
 	// list validation
+	if listSchema != nil && itemSchemas == nil && constraint.parallel(len(items)) {
+		constraint.validateParallel(len(items), func(i int) []SchemaError {
+			c := constraint.child(listSchema, "items")
+			c.Validate(items[i], fmt.Sprintf("%s[%d]", path, i))
+			return c.Errors()
+		})
+		return
+	}
+
 	if listSchema != nil && itemSchemas == nil {
-		c := NewBaseConstraint(listSchema)
+		c := constraint.child(listSchema, "items")
 		for i, item := range items {
+			if c.done() || c.aborted() || c.canceled() || c.exhausted() {
+				break
+			}
 			c.Validate(item, fmt.Sprintf("%s[%d]", path, i))
+			if c.replaced {
+				items[i] = c.replacement
+			}
 		}
 		constraint.addErrors(c.Errors())
 		return
 	}
 
 	// tuple validation
-	additionSchema, isAllowAddition, existAddition := constraint.schema.AdditionalItems()
-	itemSchemaSize := len(itemSchemas)
-
 	for i, item := range items {
-		subPath := fmt.Sprintf("%s[%d]", path, i)
-
-		if i >= itemSchemaSize {
-			// additional schema not exists
-			if !existAddition {
-				constraint.addError(newError(ArrayAdditionalItemError, subPath))
-				continue
-			}
-
-			// additional schema is object
-			if existAddition && additionSchema != nil {
-				c := NewBaseConstraint(additionSchema)
-				c.Validate(item, subPath)
-				constraint.addErrors(c.Errors())
-				continue
-			}
-
-			// additional schema is true
-			if existAddition && additionSchema == nil && isAllowAddition {
-				continue
-			}
+		if constraint.stopped() {
+			return
+		}
 
-			// additional schema is false
-			if existAddition && additionSchema == nil && !isAllowAddition {
-				constraint.addError(newError(ArrayAdditionalItemError, subPath))
-				continue
-			}
+		subPath := fmt.Sprintf("%s[%d]", path, i)
+		one, tokens, allowed := itemSchema(constraint.schema, i)
+		if !allowed {
+			constraint.addError(newError(ArrayAdditionalItemError, subPath))
+			continue
+		}
+		if one == nil {
+			continue
 		}
 
-		c := NewBaseConstraint(itemSchemas[i])
+		c := constraint.child(one, tokens...)
 		c.Validate(item, subPath)
 		constraint.addErrors(c.Errors())
+		if c.replaced {
+			items[i] = c.replacement
+		}
+	}
+}
+
+// itemSchema returns the schema of the item i of the arrays validated against s
+// and the keyword tokens locating it, or nil if the item can be any value.
+// allowed is false if i is past the schemas of an items array and
+// additionalItems is false; as in the specification, such items are allowed
+// when additionalItems is absent.
+func itemSchema(s Schema, i int) (schema Schema, tokens []string, allowed bool) {
+	listSchema, itemSchemas, exist := s.Items()
+	switch {
+	case !exist:
+		return nil, nil, true
+	case listSchema != nil && itemSchemas == nil:
+		return listSchema, []string{"items"}, true
+	case i < len(itemSchemas):
+		return itemSchemas[i], []string{"items", strconv.Itoa(i)}, true
+	}
+
+	additionSchema, allowAddition, existAddition := s.AdditionalItems()
+	switch {
+	case additionSchema != nil:
+		return additionSchema, []string{"additionalItems"}, true
+	case existAddition && !allowAddition:
+		return nil, nil, false
 	}
+	return nil, nil, true
 }
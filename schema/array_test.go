@@ -17,20 +17,26 @@ func TestArrayConstraintUniqueItems(t *testing.T) {
 				json.Number("1"),
 				json.Number("1"),
 			},
-			expectedErrors: nil,
+			expectedErrors: []SchemaError{
+				newError(ArrayUniqueItemError, "a[1]"),
+			},
 		},
 		{
 			input: []interface{}{
-				"a", "a",
+				"a", "b", "a",
+			},
+			expectedErrors: []SchemaError{
+				newError(ArrayUniqueItemError, "a[2]"),
 			},
-			expectedErrors: nil,
 		},
 		{
 			input: []interface{}{
 				map[string]interface{}{},
 				map[string]interface{}{},
 			},
-			expectedErrors: nil,
+			expectedErrors: []SchemaError{
+				newError(ArrayUniqueItemError, "a[1]"),
+			},
 		},
 		{
 			input: []interface{}{
@@ -43,7 +49,9 @@ func TestArrayConstraintUniqueItems(t *testing.T) {
 					"b": 2,
 				},
 			},
-			expectedErrors: nil,
+			expectedErrors: []SchemaError{
+				newError(ArrayUniqueItemError, "a[1]"),
+			},
 		},
 		{
 			input: []interface{}{
@@ -57,17 +65,19 @@ func TestArrayConstraintUniqueItems(t *testing.T) {
 					"c": 3,
 				},
 			},
-			expectedErrors: []SchemaError{
-				&schemaError{ArrayUniqueItemError, "a[1]"},
-			},
+			expectedErrors: nil,
 		},
 	}
 
-	c := NewArrayConstraint(nil)
 	path := "a"
 	for _, test := range tests {
+		c := NewArrayConstraint(Schema{"uniqueItems": true})
 		c.validateUniqueItem(test.input, path)
 		assert.Equal(t, test.expectedErrors, c.Errors())
+
+		c = NewArrayConstraint(Schema{"uniqueItems": false})
+		c.validateUniqueItem(test.input, path)
+		assert.Nil(t, c.Errors())
 	}
 }
 
@@ -81,91 +91,103 @@ func TestArrayConstraintItems(t *testing.T) {
 	}{
 		{
 			itemSchema: Schema{
-				"items": map[string]interface{} {
+				"items": map[string]interface{}{
 					"type": "integer",
 				},
 			},
-			value: []interface{} {json.Number("1")},
+			value:          []interface{}{json.Number("1")},
 			expectedErrors: nil,
 		},
 		{
+			// the items past the tuple are allowed without additionalItems
 			itemSchema: Schema{
-				"items": map[string]interface{} {
+				"items": []interface{}{
+					map[string]interface{}{
+						"type": "integer",
+					},
+				},
+			},
+			value:          []interface{}{json.Number("1"), "str"},
+			expectedErrors: nil,
+		},
+		{
+			itemSchema: Schema{
+				"items": map[string]interface{}{
 					"type": "integer",
 				},
 			},
-			value: []interface{} {json.Number("1.1")},
+			value: []interface{}{json.Number("1.1")},
 			expectedErrors: []SchemaError{
-				newError(TypeNotMatchError, "a[0]"),
+				newError(TypeNotMatchError, "a[0]").withParam("expected", JsonInteger),
 			},
 		},
 	}
 
 	for _, test := range listTests {
-		c :=  NewArrayConstraint(test.itemSchema)
+		c := NewArrayConstraint(test.itemSchema)
 		c.validateItems(test.value, path)
 
 		assert.Equal(t, test.expectedErrors, c.Errors())
 	}
 
-	tupleTests := []struct{
+	tupleTests := []struct {
 		itemSchema     Schema
 		value          []interface{}
 		expectedErrors []SchemaError
 	}{
 		{
 			itemSchema: Schema{
-				"items": []interface{} {
-					map[string]interface{} {
+				"items": []interface{}{
+					map[string]interface{}{
 						"type": "integer",
 					},
 				},
 			},
-			value: []interface{} {json.Number("1")},
+			value:          []interface{}{json.Number("1")},
 			expectedErrors: nil,
 		},
 		{
 			itemSchema: Schema{
-				"items": []interface{} {
-					map[string]interface{} {
+				"items": []interface{}{
+					map[string]interface{}{
 						"type": "integer",
 					},
 				},
 			},
-			value: []interface{} {json.Number("1.1")},
+			value: []interface{}{json.Number("1.1")},
 			expectedErrors: []SchemaError{
-				newError(TypeNotMatchError, "a[0]"),
+				newError(TypeNotMatchError, "a[0]").withParam("expected", JsonInteger),
 			},
 		},
 		{
 			itemSchema: Schema{
-				"items": []interface{} {
-					map[string]interface{} {
+				"items": []interface{}{
+					map[string]interface{}{
 						"type": "integer",
 					},
-					map[string]interface{} {
+					map[string]interface{}{
 						"type": "string",
 					},
 				},
 			},
-			value: []interface{} {
+			value: []interface{}{
 				json.Number("1"),
 				json.Number("1"),
 			},
 			expectedErrors: []SchemaError{
-				newError(TypeNotMatchError, "a[1]"),
+				newError(TypeNotMatchError, "a[1]").withParam("expected", JsonString),
 			},
 		},
 		{
 			itemSchema: Schema{
-				"items": []interface{} {
-					map[string]interface{} {
+				"items": []interface{}{
+					map[string]interface{}{
 						"type": "integer",
 					},
 				},
 				"additionalItems": true,
 			},
-			value: []interface{} {
+			value: []interface{}{
 				json.Number("1"),
 				"str",
 			},
@@ -173,14 +195,14 @@ func TestArrayConstraintItems(t *testing.T) {
 		},
 		{
 			itemSchema: Schema{
-				"items": []interface{} {
-					map[string]interface{} {
+				"items": []interface{}{
+					map[string]interface{}{
 						"type": "integer",
 					},
 				},
 				"additionalItems": false,
 			},
-			value: []interface{} {
+			value: []interface{}{
 				json.Number("1"),
 				"str",
 			},
@@ -190,30 +212,30 @@ func TestArrayConstraintItems(t *testing.T) {
 		},
 		{
 			itemSchema: Schema{
-				"items": []interface{} {
-					map[string]interface{} {
+				"items": []interface{}{
+					map[string]interface{}{
 						"type": "integer",
 					},
 				},
-				"additionalItems": map[string]interface{} {
+				"additionalItems": map[string]interface{}{
 					"type": "string",
 				},
 			},
-			value: []interface{} {
+			value: []interface{}{
 				json.Number("1"),
 				"str",
 				json.Number("2"),
 			},
 			expectedErrors: []SchemaError{
-				newError(TypeNotMatchError, "a[2]"),
+				newError(TypeNotMatchError, "a[2]").withParam("expected", JsonString),
 			},
 		},
 	}
 
 	for _, test := range tupleTests {
-		c :=  NewArrayConstraint(test.itemSchema)
+		c := NewArrayConstraint(test.itemSchema)
 		c.validateItems(test.value, path)
 
 		assert.Equal(t, test.expectedErrors, c.Errors())
 	}
-}
\ No newline at end of file
+}
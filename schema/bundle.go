@@ -0,0 +1,235 @@
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Bundle loads the schema at rootURI and returns a copy of it that embeds every
+// schema document its $refs reference, directly or through other documents. The
+// documents are added to $defs, or to definitions if the $schema of the root is
+// draft 4, 6 or 7, under a name derived from their URI, with their absolute URI
+// as $id. The $refs of the root to them are rewritten to point into $defs, and
+// the ones of the embedded documents are kept, as they resolve against the $id
+// of their document. The root is loaded with the loader of the options, unless
+// it was added with WithSchema, and the other documents are only loaded if
+// WithRemoteRefs or WithFileRefs allows it.
+func Bundle(rootURI string, opts ...Option) (Schema, error) {
+	o := newOptions(opts...)
+	root, ok := o.schemas[rootURI]
+	if !ok {
+		loader := o.loader
+		if loader == nil {
+			loader = DefaultLoader
+		}
+		var err error
+		if root, err = loader.Load(rootURI); err != nil {
+			return nil, fmt.Errorf("Bundle Error: can not load %s: %s", rootURI, err)
+		}
+	}
+
+	refs := newRegistry(root, o)
+	refs.add(rootURI, root)
+	b := &bundler{refs: refs, root: rootURI, names: make(map[string]string)}
+
+	// the documents are collected from the root, then from each collected one
+	if err := b.collect(rootURI); err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(b.docs); i++ {
+		if err := b.collect(b.docs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	key := "$defs"
+	if draft, _ := root["$schema"].(string); oldDraftRegexp.MatchString(draft) {
+		key = "definitions"
+	}
+
+	bundle := copyValue(map[string]interface{}(root)).(map[string]interface{})
+	defs, _ := bundle[key].(map[string]interface{})
+	if defs == nil {
+		defs = make(map[string]interface{})
+	}
+
+	// the root's own definitions keep their names
+	taken := make(map[string]bool)
+	for name := range defs {
+		taken[name] = true
+	}
+	for _, doc := range b.docs {
+		b.names[doc] = uniqueName(docName(doc), taken)
+	}
+
+	err := walkScoped(root, "", schemaBase(rootURI, root), func(pointer string, base string, s Schema) error {
+		ref, ok := s.Ref()
+		if !ok {
+			return nil
+		}
+		rewritten, err := b.rewrite(base, ref, key)
+		if err != nil {
+			return fmt.Errorf("Bundle Error: $ref %s at #%s: %s", ref, pointer, err)
+		}
+		if rewritten != "" {
+			lookupPointer(bundle, pointer)["$ref"] = rewritten
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// draft 4 names $id id
+	idKey := "$id"
+	if draft, _ := root["$schema"].(string); strings.Contains(draft, "draft-04") || strings.Contains(draft, "draft-03") {
+		idKey = "id"
+	}
+	for _, doc := range b.docs {
+		res := refs.docs[doc]
+		embedded := copyValue(map[string]interface{}(res.schema)).(map[string]interface{})
+		delete(embedded, "$id")
+		delete(embedded, "id")
+		embedded[idKey] = res.base
+		defs[b.names[doc]] = embedded
+	}
+	if len(b.docs) > 0 {
+		bundle[key] = defs
+	}
+
+	// the embedded documents referencing the root need its URI
+	if _, ok := Schema(bundle).ID(); !ok && b.rootReferenced {
+		bundle[idKey] = rootURI
+	}
+
+	return Schema(bundle), nil
+}
+
+// oldDraftRegexp matches the $schema of the drafts using definitions.
+var oldDraftRegexp = regexp.MustCompile(`draft-0[3-7]`)
+
+type bundler struct {
+	refs *registry
+	root string
+	// docs are the URIs of the documents to embed, in the order they are found,
+	// and names their names in $defs.
+	docs  []string
+	names map[string]string
+	// rootReferenced is set if a document references the root.
+	rootReferenced bool
+}
+
+// collect adds the documents referenced by the document at uri.
+func (b *bundler) collect(uri string) error {
+	res := b.refs.docs[uri]
+	return walkScoped(res.schema, "", res.base, func(pointer string, base string, s Schema) error {
+		ref, ok := s.Ref()
+		if !ok {
+			return nil
+		}
+		target, err := b.refs.resolve(base, ref)
+		if err != nil {
+			return fmt.Errorf("Bundle Error: $ref %s at %s#%s: %s", ref, uri, pointer, err)
+		}
+		if !b.external(target.doc) {
+			b.rootReferenced = b.rootReferenced || b.external(uri)
+		} else if !containsString(b.docs, target.doc) {
+			b.docs = append(b.docs, target.doc)
+		}
+		return nil
+	})
+}
+
+// external reports whether the document at doc is not the root.
+func (b *bundler) external(doc string) bool {
+	return doc != "" && doc != b.root
+}
+
+// rewrite returns the $ref of the bundle for ref, or an empty string if ref
+// points into the root.
+func (b *bundler) rewrite(base string, ref string, key string) (string, error) {
+	target, err := b.refs.resolve(base, ref)
+	if err != nil {
+		return "", err
+	}
+	if !b.external(target.doc) {
+		return "", nil
+	}
+
+	prefix := "#/" + key + "/" + escapePointer(b.names[target.doc])
+
+	// a JSON pointer into the document is kept, otherwise the subschema is
+	// looked for in the document
+	u, err := url.Parse(resolveURI(base, ref))
+	if err != nil {
+		return "", err
+	}
+	fragment := u.Fragment
+	u.Fragment = ""
+	if u.String() == target.doc && (fragment == "" || strings.HasPrefix(fragment, "/")) {
+		return prefix + fragment, nil
+	}
+
+	var found string
+	Walk(b.refs.docs[target.doc].schema, func(pointer string, s Schema) error {
+		if found == "" && reflect.ValueOf(s).Pointer() == reflect.ValueOf(target.schema).Pointer() {
+			found = pointer
+		}
+		return nil
+	})
+	if found == "" && reflect.ValueOf(b.refs.docs[target.doc].schema).Pointer() != reflect.ValueOf(target.schema).Pointer() {
+		return "", fmt.Errorf("can not locate %s in %s", ref, target.doc)
+	}
+	return prefix + found, nil
+}
+
+// docName returns the name in $defs of the document at uri, from the last
+// segment of its path without extension.
+func docName(uri string) string {
+	u, err := url.Parse(uri)
+	name := ""
+	if err == nil {
+		name = strings.TrimSuffix(path.Base(u.Path), path.Ext(u.Path))
+	}
+	if name == "" || name == "." || name == "/" {
+		return "schema"
+	}
+	return name
+}
+
+// uniqueName returns name, or name followed by a number if it is taken, and
+// marks it as taken.
+func uniqueName(name string, taken map[string]bool) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// lookupPointer returns the object at the JSON pointer of v.
+func lookupPointer(v map[string]interface{}, pointer string) map[string]interface{} {
+	if pointer == "" {
+		return v
+	}
+
+	var current interface{} = v
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = unescapePointer(token)
+		switch c := current.(type) {
+		case map[string]interface{}:
+			current = c[token]
+		case []interface{}:
+			var i int
+			fmt.Sscanf(token, "%d", &i)
+			current = c[i]
+		}
+	}
+	obj, _ := current.(map[string]interface{})
+	return obj
+}
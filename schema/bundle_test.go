@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mapLoader loads the schemas of a map from their URI.
+func mapLoader(t *testing.T, docs map[string]string) Loader {
+	return LoaderFunc(func(uri string) (Schema, error) {
+		doc, ok := docs[uri]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		s, err := deserializeSchema(doc)
+		assert.NoError(t, err)
+		return s, nil
+	})
+}
+
+func TestBundle(t *testing.T) {
+	loader := mapLoader(t, map[string]string{
+		"http://example.com/root.json": `{
+			"type": "object",
+			"properties": {
+				"id": {"$ref": "#/$defs/person"},
+				"owner": {"$ref": "person.json"},
+				"name": {"$ref": "person.json#/definitions/name"},
+				"pet": {"$ref": "pets/person.json"}
+			},
+			"$defs": {"person": {"type": "integer"}}
+		}`,
+		"http://example.com/person.json": `{
+			"type": "object",
+			"properties": {"name": {"$ref": "#/definitions/name"}, "pet": {"$ref": "pets/person.json"}},
+			"definitions": {"name": {"type": "string", "maxLength": 3}}
+		}`,
+		"http://example.com/pets/person.json": `{
+			"$id": "person.json",
+			"properties": {"id": {"$ref": "../root.json#/$defs/person"}}
+		}`,
+	})
+
+	bundle, err := Bundle("http://example.com/root.json", WithLoader(loader), WithRemoteRefs())
+	assert.NoError(t, err)
+
+	expected, err := deserializeSchema(`{
+		"$id": "http://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"id": {"$ref": "#/$defs/person"},
+			"owner": {"$ref": "#/$defs/person_2"},
+			"name": {"$ref": "#/$defs/person_2/definitions/name"},
+			"pet": {"$ref": "#/$defs/person_3"}
+		},
+		"$defs": {
+			"person": {"type": "integer"},
+			"person_2": {
+				"$id": "http://example.com/person.json",
+				"type": "object",
+				"properties": {"name": {"$ref": "#/definitions/name"}, "pet": {"$ref": "pets/person.json"}},
+				"definitions": {"name": {"type": "string", "maxLength": 3}}
+			},
+			"person_3": {
+				"$id": "http://example.com/pets/person.json",
+				"properties": {"id": {"$ref": "../root.json#/$defs/person"}}
+			}
+		}
+	}`)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, bundle)
+
+	// the bundle validates as the root, without loading anything
+	root, err := loader.Load("http://example.com/root.json")
+	assert.NoError(t, err)
+	root["$id"] = "http://example.com/root.json"
+	for _, doc := range []string{
+		`{"id": 1, "owner": {"name": "abc", "pet": {"id": 2}}, "name": "abc"}`,
+		`{"id": "1", "owner": {"name": "abcd", "pet": {"id": "2"}}, "name": 1, "pet": {"id": true}}`,
+	} {
+		v, err := deserializeValue(doc)
+		assert.NoError(t, err)
+
+		bundled := NewValidator(bundle).Validate(v)
+		assert.Equal(t, errorStrings(NewValidator(root, WithLoader(loader), WithRemoteRefs()).Validate(v).Errors), errorStrings(bundled.Errors), doc)
+	}
+}
+
+func TestBundleDraft(t *testing.T) {
+	tests := []struct {
+		draft    string
+		expected string
+	}{
+		{
+			draft: "http://json-schema.org/draft-07/schema#",
+			expected: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"$ref": "#/definitions/string",
+				"definitions": {"string": {"$id": "http://example.com/string.json", "type": "string"}}
+			}`,
+		},
+		{
+			draft: "http://json-schema.org/draft-04/schema#",
+			expected: `{
+				"$schema": "http://json-schema.org/draft-04/schema#",
+				"$ref": "#/definitions/string",
+				"definitions": {"string": {"id": "http://example.com/string.json", "type": "string"}}
+			}`,
+		},
+		{
+			draft: "https://json-schema.org/draft/2020-12/schema",
+			expected: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$ref": "#/$defs/string",
+				"$defs": {"string": {"$id": "http://example.com/string.json", "type": "string"}}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		root := Schema{"$schema": test.draft, "$ref": "string.json"}
+		loader := mapLoader(t, map[string]string{"http://example.com/string.json": `{"type": "string"}`})
+
+		bundle, err := Bundle("http://example.com/root.json", WithSchema("http://example.com/root.json", root), WithLoader(loader), WithRemoteRefs())
+		assert.NoError(t, err)
+		expected, err := deserializeSchema(test.expected)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, bundle, test.draft)
+	}
+}
+
+func TestBundleError(t *testing.T) {
+	loader := mapLoader(t, map[string]string{
+		"http://example.com/root.json": `{"properties": {"a": {"$ref": "missing.json"}}}`,
+	})
+
+	_, err := Bundle("http://example.com/root.json", WithLoader(loader), WithRemoteRefs())
+	assert.EqualError(t, err, "Bundle Error: $ref missing.json at http://example.com/root.json#/properties/a: "+
+		"Resolve Error: can not load http://example.com/missing.json: not found")
+
+	_, err = Bundle("http://example.com/other.json", WithLoader(loader), WithRemoteRefs())
+	assert.EqualError(t, err, "Bundle Error: can not load http://example.com/other.json: not found")
+}
@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// DefaultArraySeparator separates the items of the strings coerced to arrays,
+// when WithCoercion is given an empty separator.
+const DefaultArraySeparator = ","
+
+var (
+	integerRegexp = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	numberRegexp  = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+// coerce converts the string v to the first type of b's schema it can represent.
+// ok is false if v is not a string, a string is allowed, or no type fits. The
+// subschemas of allOf, anyOf, oneOf and not do not coerce, as they may not
+// match.
+func (b *baseConstraint) coerce(v interface{}) (coerced interface{}, ok bool) {
+	if b.opts == nil || !b.opts.coercion || b.opts.probing {
+		return nil, false
+	}
+
+	str, isString := v.(string)
+	if !isString {
+		return nil, false
+	}
+
+	t, types, exist := b.schema.Type()
+	if !exist {
+		return nil, false
+	}
+	if t != "" {
+		types = []JsonType{t}
+	}
+	for _, t := range types {
+		if t == JsonString {
+			return nil, false
+		}
+	}
+
+	for _, t := range types {
+		switch t {
+		case JsonInteger:
+			if integerRegexp.MatchString(str) {
+				return json.Number(str), true
+			}
+		case JsonNumber:
+			if numberRegexp.MatchString(str) {
+				return json.Number(str), true
+			}
+		case JsonBoolean:
+			if str == "true" || str == "false" {
+				return str == "true", true
+			}
+		case JsonNull:
+			if str == "" {
+				return nil, true
+			}
+		case JsonArray:
+			arr := []interface{}{}
+			if str != "" {
+				for _, item := range strings.Split(str, b.opts.separator) {
+					arr = append(arr, item)
+				}
+			}
+			return arr, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoercion(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"type": "object",
+		"properties": {
+			"page": {"type": "integer", "minimum": 1},
+			"ratio": {"type": "number"},
+			"debug": {"type": "boolean"},
+			"limit": {"type": ["integer", "null"]},
+			"ids": {"type": "array", "items": {"type": "integer"}},
+			"tags": {"type": "array"},
+			"name": {"type": ["string", "integer"]},
+			"ref": {"$ref": "#/definitions/flag"}
+		},
+		"definitions": {
+			"flag": {"type": "boolean"}
+		}
+	}`)
+	assert.NoError(t, err)
+
+	query := map[string]interface{}{
+		"page":  "2",
+		"ratio": "3",
+		"debug": "true",
+		"limit": "",
+		"ids":   "1;2;3",
+		"tags":  "",
+		"name":  "42",
+		"ref":   "false",
+	}
+	res := NewValidator(s, WithCoercion(";")).Validate(query)
+	assert.True(t, res.Valid())
+	assert.Equal(t, map[string]interface{}{
+		"page": json.Number("2"),
+		// an integer is a number too
+		"ratio": json.Number("3"),
+		"debug": true,
+		"limit": nil,
+		"ids":   []interface{}{json.Number("1"), json.Number("2"), json.Number("3")},
+		"tags":  []interface{}{},
+		"name":  "42",
+		"ref":   false,
+	}, res.Value)
+
+	// the coerced values are validated, and the strings that can not be coerced
+	// are reported as they are
+	res = NewValidator(s, WithCoercion("")).Validate(map[string]interface{}{
+		"page":  "0",
+		"debug": "yes",
+		"ids":   "1,a",
+	})
+	assert.Equal(t, []SchemaError{
+		newError(TypeNotMatchError, "$.debug").withParam("expected", JsonBoolean),
+		newError(TypeNotMatchError, "$.ids[1]").withParam("expected", JsonInteger),
+		newError(NumericMinimumError, "$.page").withParam("limit", float64(1)),
+	}, res.Errors)
+
+	// without the option, strings are not coerced
+	res = NewValidator(s).Validate(map[string]interface{}{"page": "2"})
+	assert.Equal(t, []SchemaError{
+		newError(TypeNotMatchError, "$.page").withParam("expected", JsonInteger),
+	}, res.Errors)
+}
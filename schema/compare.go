@@ -0,0 +1,460 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Compatibility tells which side of a schema change can be upgraded first.
+type Compatibility string
+
+const (
+	// BackwardCompatible changes keep the values valid against the old schema
+	// valid against the new one, so the consumers can be upgraded first.
+	BackwardCompatible = Compatibility("backward")
+	// ForwardCompatible changes keep the values valid against the new schema
+	// valid against the old one, so the producers can be upgraded first.
+	ForwardCompatible = Compatibility("forward")
+	// Breaking changes are neither backward nor forward compatible.
+	Breaking = Compatibility("breaking")
+)
+
+// ChangeKind names what a Change changes.
+type ChangeKind string
+
+const (
+	// ChangeType changes the types of type.
+	ChangeType = ChangeKind("type")
+	// ChangeEnum changes the values of enum or const.
+	ChangeEnum = ChangeKind("enum")
+	// ChangeConstraint changes another keyword constraining the values, such as
+	// maxLength or additionalProperties.
+	ChangeConstraint = ChangeKind("constraint")
+	// ChangeRequired adds or removes a property of required.
+	ChangeRequired = ChangeKind("required")
+	// ChangePropertyAdded adds a property to properties.
+	ChangePropertyAdded = ChangeKind("property-added")
+	// ChangePropertyRemoved removes a property from properties.
+	ChangePropertyRemoved = ChangeKind("property-removed")
+)
+
+// Change is a change of the values a schema accepts, found by Compare.
+type Change struct {
+	Kind          ChangeKind
+	Compatibility Compatibility
+	// Pointer is the JSON pointer of the changed subschema, the $refs on the way
+	// being followed.
+	Pointer string
+	Keyword string
+	Message string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("#%s: %s (%s)", c.Pointer, c.Message, c.Compatibility)
+}
+
+// upperBounds and lowerBounds are the keywords bounding the values.
+var (
+	upperBounds = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+	lowerBounds = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+)
+
+// comparedKeywords are the keywords Compare does not compare as a whole, as
+// they are compared by their meaning or do not constrain the values.
+var comparedKeywords = []string{
+	"$anchor", "$comment", "$defs", "$id", "$ref", "$schema", "additionalItems",
+	"additionalProperties", "allOf", "anyOf", "const", "default", "definitions",
+	"deprecated", "description", "enum", "errorMessage", "examples", "id", "items",
+	"properties", "readOnly", "required", "title", "type", "uniqueItems", "writeOnly",
+}
+
+// Compare returns the changes from old to new of the values they accept, in a
+// stable order. The subschemas are compared keyword by keyword, after following
+// their $refs in their own schema, so a subschema moved to the definitions of
+// new is not a change. The changes of keywords whose effect can not be told,
+// such as not or oneOf, are reported as breaking.
+func Compare(old Schema, new Schema, opts ...Option) []Change {
+	c := &comparer{
+		oldRefs: NewValidator(old, opts...).refs,
+		newRefs: NewValidator(new, opts...).refs,
+		opts:    newOptions(opts...),
+		visited: make(map[[2]uintptr]bool),
+	}
+	c.compare(old, schemaBase("", old), new, schemaBase("", new), "")
+	return c.changes
+}
+
+type comparer struct {
+	oldRefs *registry
+	newRefs *registry
+	opts    *options
+	// visited are the pairs of subschemas already compared.
+	visited map[[2]uintptr]bool
+	changes []Change
+}
+
+func (c *comparer) report(kind ChangeKind, compatibility Compatibility, pointer string, keyword string, format string, args ...interface{}) {
+	c.changes = append(c.changes, Change{
+		Kind:          kind,
+		Compatibility: compatibility,
+		Pointer:       pointer,
+		Keyword:       keyword,
+		Message:       fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *comparer) compare(old Schema, oldBase string, new Schema, newBase string, pointer string) {
+	old, oldBase = c.oldRefs.follow(old, oldBase, c.opts.maxRefDepth)
+	new, newBase = c.newRefs.follow(new, newBase, c.opts.maxRefDepth)
+
+	pair := [2]uintptr{reflect.ValueOf(old).Pointer(), reflect.ValueOf(new).Pointer()}
+	if c.visited[pair] {
+		return
+	}
+	c.visited[pair] = true
+
+	c.compareType(old, new, pointer)
+	c.compareEnum(old, new, pointer)
+	c.compareBounds(old, new, pointer)
+	c.compareKeywords(old, new, pointer)
+	c.compareRequired(old, new, pointer)
+	c.compareProperties(old, oldBase, new, newBase, pointer)
+	c.compareItems(old, oldBase, new, newBase, pointer)
+
+	for _, keyword := range []string{"allOf", "anyOf"} {
+		oldSubs, _ := old[keyword].([]interface{})
+		newSubs, _ := new[keyword].([]interface{})
+		if len(oldSubs) != len(newSubs) {
+			c.report(ChangeConstraint, Breaking, pointer, keyword, "%s changed from %d to %d subschemas", keyword, len(oldSubs), len(newSubs))
+			continue
+		}
+		for i := range oldSubs {
+			c.compareSchemas(oldSubs[i], oldBase, newSubs[i], newBase, fmt.Sprintf("%s/%s/%d", pointer, keyword, i))
+		}
+	}
+}
+
+// compareSchemas compares the subschemas old and new, which are not compared
+// as a whole if they are not both schemas.
+func (c *comparer) compareSchemas(old interface{}, oldBase string, new interface{}, newBase string, pointer string) {
+	oldSchema, oldOk := old.(map[string]interface{})
+	newSchema, newOk := new.(map[string]interface{})
+	switch {
+	case oldOk && newOk:
+		c.compare(Schema(oldSchema), schemaBase(oldBase, oldSchema), Schema(newSchema), schemaBase(newBase, newSchema), pointer)
+	case !reflect.DeepEqual(old, new):
+		c.report(ChangeConstraint, Breaking, pointer, "", "schema changed from %s to %s", jsonString(old), jsonString(new))
+	}
+}
+
+// allTypes are the types of the values, number standing for the numbers that
+// are not integers.
+var allTypes = []JsonType{JsonNull, JsonBoolean, JsonInteger, JsonNumber, JsonString, JsonArray, JsonObject}
+
+// accepts reports whether the type keyword of s accepts the values of t.
+func accepts(s Schema, t JsonType) bool {
+	one, types, exist := s.Type()
+	if !exist {
+		return true
+	}
+	if one != "" {
+		types = []JsonType{one}
+	}
+	return containsType(types, t) || (t == JsonInteger && containsType(types, JsonNumber))
+}
+
+func (c *comparer) compareType(old Schema, new Schema, pointer string) {
+	narrowed, widened := false, false
+	for _, t := range allTypes {
+		narrowed = narrowed || (accepts(old, t) && !accepts(new, t))
+		widened = widened || (!accepts(old, t) && accepts(new, t))
+	}
+	if narrowed || widened {
+		c.report(ChangeType, compatibility(narrowed, widened), pointer, "type", "type changed from %s to %s", describeType(old), describeType(new))
+	}
+}
+
+func describeType(s Schema) string {
+	if _, ok := s["type"]; !ok {
+		return "any"
+	}
+	return jsonString(s["type"])
+}
+
+// enumValues returns the values const or enum allow, and the keyword.
+func enumValues(s Schema) ([]interface{}, string, bool) {
+	if v, ok := s["const"]; ok {
+		return []interface{}{v}, "const", true
+	}
+	if values, ok := s["enum"].([]interface{}); ok {
+		return values, "enum", true
+	}
+	return nil, "", false
+}
+
+func (c *comparer) compareEnum(old Schema, new Schema, pointer string) {
+	oldValues, oldKeyword, oldExist := enumValues(old)
+	newValues, newKeyword, newExist := enumValues(new)
+
+	switch {
+	case !oldExist && !newExist:
+	case !oldExist:
+		c.report(ChangeEnum, ForwardCompatible, pointer, newKeyword, "%s %s added", newKeyword, jsonString(newValues))
+	case !newExist:
+		c.report(ChangeEnum, BackwardCompatible, pointer, oldKeyword, "%s %s removed", oldKeyword, jsonString(oldValues))
+	default:
+		removed := missingValues(oldValues, newValues)
+		added := missingValues(newValues, oldValues)
+		var parts []string
+		if len(removed) > 0 {
+			parts = append(parts, "no longer allows "+jsonString(removed))
+		}
+		if len(added) > 0 {
+			parts = append(parts, "now allows "+jsonString(added))
+		}
+		if len(parts) > 0 {
+			c.report(ChangeEnum, compatibility(len(removed) > 0, len(added) > 0), pointer, newKeyword, "%s %s", newKeyword, strings.Join(parts, ", "))
+		}
+	}
+}
+
+// missingValues returns the values of a that are not in b.
+func missingValues(a []interface{}, b []interface{}) []interface{} {
+	var missing []interface{}
+	for _, v := range a {
+		found := false
+		for _, w := range b {
+			found = found || reflect.DeepEqual(v, w)
+		}
+		if !found {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+func (c *comparer) compareBounds(old Schema, new Schema, pointer string) {
+	for _, bounds := range []struct {
+		keywords []string
+		upper    bool
+	}{{upperBounds, true}, {lowerBounds, false}} {
+		for _, keyword := range bounds.keywords {
+			oldValue, oldExist := old[keyword]
+			newValue, newExist := new[keyword]
+
+			// the boolean exclusiveMaximum and exclusiveMinimum of draft 4
+			if (oldExist && !isNumber(oldValue)) || (newExist && !isNumber(newValue)) {
+				c.compareExclusive(old, new, pointer, keyword)
+				continue
+			}
+
+			switch {
+			case !oldExist && !newExist:
+			case !oldExist:
+				c.report(ChangeConstraint, ForwardCompatible, pointer, keyword, "%s %v added", keyword, newValue)
+			case !newExist:
+				c.report(ChangeConstraint, BackwardCompatible, pointer, keyword, "%s %v removed", keyword, oldValue)
+			case toFloat(oldValue) != toFloat(newValue):
+				tightened := (toFloat(newValue) < toFloat(oldValue)) == bounds.upper
+				if tightened {
+					c.report(ChangeConstraint, ForwardCompatible, pointer, keyword, "%s tightened from %v to %v", keyword, oldValue, newValue)
+				} else {
+					c.report(ChangeConstraint, BackwardCompatible, pointer, keyword, "%s loosened from %v to %v", keyword, oldValue, newValue)
+				}
+			}
+		}
+	}
+}
+
+// compareExclusive compares the boolean exclusiveMaximum or exclusiveMinimum of
+// draft 4, a missing one being false. A true one excludes the bound, so setting
+// it tightens the schema, and unsetting it loosens it. A change from or to a
+// number is compared as any other keyword.
+func (c *comparer) compareExclusive(old Schema, new Schema, pointer string, keyword string) {
+	oldValue, oldExist := old[keyword]
+	newValue, newExist := new[keyword]
+	oldExclusive, oldBool := oldValue.(bool)
+	newExclusive, newBool := newValue.(bool)
+	if (oldExist && !oldBool) || (newExist && !newBool) {
+		c.compareKeyword(old, new, pointer, keyword)
+		return
+	}
+
+	if !oldExclusive && newExclusive {
+		c.report(ChangeConstraint, ForwardCompatible, pointer, keyword, "%s set", keyword)
+	} else if oldExclusive && !newExclusive {
+		c.report(ChangeConstraint, BackwardCompatible, pointer, keyword, "%s unset", keyword)
+	}
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case float64, int, json.Number:
+		return true
+	}
+	return false
+}
+
+// compareKeywords compares the keywords that are not compared by their meaning.
+func (c *comparer) compareKeywords(old Schema, new Schema, pointer string) {
+	skipped := make(map[string]bool)
+	for _, keywords := range [][]string{comparedKeywords, upperBounds, lowerBounds} {
+		for _, keyword := range keywords {
+			skipped[keyword] = true
+		}
+	}
+
+	keywords := sortedKeys(old)
+	for _, keyword := range sortedKeys(new) {
+		if _, ok := old[keyword]; !ok {
+			keywords = append(keywords, keyword)
+		}
+	}
+	for _, keyword := range keywords {
+		if !skipped[keyword] {
+			c.compareKeyword(old, new, pointer, keyword)
+		}
+	}
+
+	oldUnique, _ := old["uniqueItems"].(bool)
+	newUnique, _ := new["uniqueItems"].(bool)
+	if !oldUnique && newUnique {
+		c.report(ChangeConstraint, ForwardCompatible, pointer, "uniqueItems", "uniqueItems added")
+	} else if oldUnique && !newUnique {
+		c.report(ChangeConstraint, BackwardCompatible, pointer, "uniqueItems", "uniqueItems removed")
+	}
+}
+
+// compareKeyword compares the values of keyword, as a new keyword tightens the
+// schema, and a removed one loosens it. The meaning of a changed value is not
+// interpreted, so the change of a keyword such as oneOf, not or pattern is
+// conservatively reported as Breaking, even if it only tightens or loosens the
+// schema.
+func (c *comparer) compareKeyword(old Schema, new Schema, pointer string, keyword string) {
+	oldValue, oldExist := old[keyword]
+	newValue, newExist := new[keyword]
+	switch {
+	case !oldExist && newExist:
+		c.report(ChangeConstraint, ForwardCompatible, pointer, keyword, "%s %s added", keyword, jsonString(newValue))
+	case oldExist && !newExist:
+		c.report(ChangeConstraint, BackwardCompatible, pointer, keyword, "%s %s removed", keyword, jsonString(oldValue))
+	case !reflect.DeepEqual(oldValue, newValue):
+		c.report(ChangeConstraint, Breaking, pointer, keyword, "%s changed from %s to %s", keyword, jsonString(oldValue), jsonString(newValue))
+	}
+}
+
+func (c *comparer) compareRequired(old Schema, new Schema, pointer string) {
+	oldRequired, _ := old.Required()
+	newRequired, _ := new.Required()
+	for _, prop := range newRequired {
+		if !containsString(oldRequired, prop) {
+			c.report(ChangeRequired, ForwardCompatible, pointer, "required", "property %s is now required", prop)
+		}
+	}
+	for _, prop := range oldRequired {
+		if !containsString(newRequired, prop) {
+			c.report(ChangeRequired, BackwardCompatible, pointer, "required", "property %s is no longer required", prop)
+		}
+	}
+}
+
+// forbidsAdditional reports whether s sets additionalProperties to false.
+func forbidsAdditional(s Schema) bool {
+	additionSchema, allowAddition, exist := s.AdditionalProperties()
+	return exist && additionSchema == nil && !allowAddition
+}
+
+func (c *comparer) compareProperties(old Schema, oldBase string, new Schema, newBase string, pointer string) {
+	oldProps, _ := old["properties"].(map[string]interface{})
+	newProps, _ := new["properties"].(map[string]interface{})
+	oldForbids, newForbids := forbidsAdditional(old), forbidsAdditional(new)
+
+	names := sortedKeys(Schema(oldProps))
+	for _, name := range sortedKeys(Schema(newProps)) {
+		if _, ok := oldProps[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		oldProp, oldExist := oldProps[name]
+		newProp, newExist := newProps[name]
+		switch {
+		case oldExist && newExist:
+			c.compareSchemas(oldProp, oldBase, newProp, newBase, pointer+"/properties/"+escapePointer(name))
+		case newExist:
+			// the property was not constrained, or not allowed at all
+			if oldForbids {
+				c.report(ChangePropertyAdded, BackwardCompatible, pointer, "properties", "property %s added", name)
+			} else {
+				c.report(ChangePropertyAdded, ForwardCompatible, pointer, "properties", "property %s added", name)
+			}
+		default:
+			if newForbids {
+				c.report(ChangePropertyRemoved, ForwardCompatible, pointer, "properties", "property %s removed", name)
+			} else {
+				c.report(ChangePropertyRemoved, BackwardCompatible, pointer, "properties", "property %s removed", name)
+			}
+		}
+	}
+
+	oldAddition, _, _ := old.AdditionalProperties()
+	newAddition, _, _ := new.AdditionalProperties()
+	switch {
+	case !oldForbids && newForbids:
+		c.report(ChangeConstraint, ForwardCompatible, pointer, "additionalProperties", "additionalProperties are no longer allowed")
+	case oldForbids && !newForbids:
+		c.report(ChangeConstraint, BackwardCompatible, pointer, "additionalProperties", "additionalProperties are allowed")
+	case oldAddition != nil && newAddition != nil:
+		c.compare(oldAddition, schemaBase(oldBase, oldAddition), newAddition, schemaBase(newBase, newAddition), pointer+"/additionalProperties")
+	case newAddition != nil:
+		c.report(ChangeConstraint, ForwardCompatible, pointer, "additionalProperties", "additionalProperties schema added")
+	case oldAddition != nil:
+		c.report(ChangeConstraint, BackwardCompatible, pointer, "additionalProperties", "additionalProperties schema removed")
+	}
+}
+
+func (c *comparer) compareItems(old Schema, oldBase string, new Schema, newBase string, pointer string) {
+	for _, keyword := range []string{"items", "additionalItems"} {
+		oldValue, oldExist := old[keyword]
+		newValue, newExist := new[keyword]
+		oldItems, oldList := oldValue.([]interface{})
+		newItems, newList := newValue.([]interface{})
+
+		switch {
+		case !oldExist || !newExist:
+			c.compareKeyword(old, new, pointer, keyword)
+		case oldList && newList && len(oldItems) == len(newItems):
+			for i := range oldItems {
+				c.compareSchemas(oldItems[i], oldBase, newItems[i], newBase, fmt.Sprintf("%s/%s/%d", pointer, keyword, i))
+			}
+		case oldList || newList:
+			c.compareKeyword(old, new, pointer, keyword)
+		default:
+			c.compareSchemas(oldValue, oldBase, newValue, newBase, pointer+"/"+keyword)
+		}
+	}
+}
+
+// compatibility returns the compatibility of a change narrowing and widening
+// the values accepted.
+func compatibility(narrowed bool, widened bool) Compatibility {
+	switch {
+	case narrowed && widened:
+		return Breaking
+	case narrowed:
+		return ForwardCompatible
+	default:
+		return BackwardCompatible
+	}
+}
+
+// jsonString returns v in JSON, for the messages.
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		old      string
+		new      string
+		expected []Change
+	}{
+		{
+			old: `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+			new: `{"type": "object", "properties": {"name": {"type": "string", "description": "the name"}}}`,
+		},
+		{
+			old: `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+			new: `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}`,
+			expected: []Change{
+				{ChangeRequired, ForwardCompatible, "", "required", "property name is now required"},
+			},
+		},
+		{
+			old: `{"properties": {"a": {"enum": ["x", "y"]}, "b": {"enum": ["x"]}, "c": {"enum": ["x"]}, "d": {"const": 1}}}`,
+			new: `{"properties": {"a": {"enum": ["x"]}, "b": {"enum": ["x", "y"]}, "c": {"enum": ["y"]}, "d": {"enum": [1, 2]}}}`,
+			expected: []Change{
+				{ChangeEnum, ForwardCompatible, "/properties/a", "enum", `enum no longer allows ["y"]`},
+				{ChangeEnum, BackwardCompatible, "/properties/b", "enum", `enum now allows ["y"]`},
+				{ChangeEnum, Breaking, "/properties/c", "enum", `enum no longer allows ["x"], now allows ["y"]`},
+				{ChangeEnum, BackwardCompatible, "/properties/d", "enum", `enum now allows [2]`},
+			},
+		},
+		{
+			old: `{"type": "string", "maxLength": 10, "minLength": 1}`,
+			new: `{"type": "string", "maxLength": 5, "pattern": "^a"}`,
+			expected: []Change{
+				{ChangeConstraint, ForwardCompatible, "", "maxLength", "maxLength tightened from 10 to 5"},
+				{ChangeConstraint, BackwardCompatible, "", "minLength", "minLength 1 removed"},
+				{ChangeConstraint, ForwardCompatible, "", "pattern", `pattern "^a" added`},
+			},
+		},
+		{
+			old: `{"properties": {"a": {}, "b": {}}, "additionalProperties": false}`,
+			new: `{"properties": {"a": {}, "c": {}}}`,
+			expected: []Change{
+				{ChangePropertyRemoved, BackwardCompatible, "", "properties", "property b removed"},
+				{ChangePropertyAdded, BackwardCompatible, "", "properties", "property c added"},
+				{ChangeConstraint, BackwardCompatible, "", "additionalProperties", "additionalProperties are allowed"},
+			},
+		},
+		{
+			old: `{"properties": {"a": {"type": "integer"}, "b": {"type": "number"}, "c": {"type": "string"}, "d": {"type": ["string", "null"]}}}`,
+			new: `{"properties": {"a": {"type": "number"}, "b": {"type": "integer"}, "c": {"type": "boolean"}, "d": {"type": "string"}}}`,
+			expected: []Change{
+				{ChangeType, BackwardCompatible, "/properties/a", "type", `type changed from "integer" to "number"`},
+				{ChangeType, ForwardCompatible, "/properties/b", "type", `type changed from "number" to "integer"`},
+				{ChangeType, Breaking, "/properties/c", "type", `type changed from "string" to "boolean"`},
+				{ChangeType, ForwardCompatible, "/properties/d", "type", `type changed from ["string","null"] to "string"`},
+			},
+		},
+		{
+			// the $refs are followed, and the keywords of unknown effect breaking
+			old: `{"items": {"$ref": "#/definitions/item"}, "definitions": {"item": {"maximum": 3}}}`,
+			new: `{"items": {"maximum": 4, "not": {"type": "null"}}}`,
+			expected: []Change{
+				{ChangeConstraint, BackwardCompatible, "/items", "maximum", "maximum loosened from 3 to 4"},
+				{ChangeConstraint, ForwardCompatible, "/items", "not", `not {"type":"null"} added`},
+			},
+		},
+		{
+			// the boolean exclusive bounds of draft 4
+			old: `{"properties": {"a": {"maximum": 3, "exclusiveMaximum": true}, "b": {"minimum": 3}, "c": {"minimum": 3, "exclusiveMinimum": true}, "d": {"maximum": 3, "exclusiveMaximum": false}}}`,
+			new: `{"properties": {"a": {"maximum": 3, "exclusiveMaximum": false}, "b": {"minimum": 3, "exclusiveMinimum": true}, "c": {"minimum": 3}, "d": {"maximum": 3, "exclusiveMaximum": 3}}}`,
+			expected: []Change{
+				{ChangeConstraint, BackwardCompatible, "/properties/a", "exclusiveMaximum", "exclusiveMaximum unset"},
+				{ChangeConstraint, ForwardCompatible, "/properties/b", "exclusiveMinimum", "exclusiveMinimum set"},
+				{ChangeConstraint, BackwardCompatible, "/properties/c", "exclusiveMinimum", "exclusiveMinimum unset"},
+				{ChangeConstraint, Breaking, "/properties/d", "exclusiveMaximum", "exclusiveMaximum changed from false to 3"},
+			},
+		},
+		{
+			// the recursive schemas are compared once
+			old: `{"properties": {"next": {"$ref": "#"}, "value": {"type": "string"}}}`,
+			new: `{"properties": {"next": {"$ref": "#"}, "value": {"type": "string", "oneOf": [{"minLength": 1}]}}}`,
+			expected: []Change{
+				{ChangeConstraint, ForwardCompatible, "/properties/value", "oneOf", `oneOf [{"minLength":1}] added`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		old, err := deserializeSchema(test.old)
+		assert.NoError(t, err)
+		new, err := deserializeSchema(test.new)
+		assert.NoError(t, err)
+
+		assert.Equal(t, test.expected, Compare(old, new), "%s -> %s", test.old, test.new)
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	change := Change{ChangeConstraint, ForwardCompatible, "/properties/a", "maxLength", "maxLength tightened from 10 to 5"}
+	assert.Equal(t, "#/properties/a: maxLength tightened from 10 to 5 (forward)", change.String())
+}
@@ -0,0 +1,292 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Compile checks s and returns a Validator for it. Unlike NewValidator, every
+// $ref of s is resolved upfront, loading the documents it references, so an
+// invalid schema is reported here rather than while validating. The errors are
+// the keywords whose value does not have the type the validation expects, such
+// as a maxLength that is not a non-negative integer or a boolean subschema, the
+// $refs that can not be resolved or are circular, and the patterns that are
+// longer than the maximum pattern length option or that the RegexpEngine can not
+// compile. The compiled patterns are kept for the validations.
+func Compile(s Schema, opts ...Option) (*Validator, error) {
+	validator := NewValidator(s, opts...)
+	o := validator.options(nil)
+
+	c := &compiler{opts: o, compiled: make(map[string]bool)}
+	if err := c.compile(validator.refs.docs[""]); err != nil {
+		return nil, err
+	}
+	return validator, nil
+}
+
+type compiler struct {
+	opts *options
+	// compiled holds the URIs of the documents already checked.
+	compiled map[string]bool
+}
+
+// compile checks the document res, and the documents its $refs reference.
+func (c *compiler) compile(res resource) error {
+	c.compiled[res.doc] = true
+
+	return walkScoped(res.schema, "", res.doc, func(pointer string, base string, s Schema) error {
+		if err := checkKeywords(res.doc, pointer, s); err != nil {
+			return err
+		}
+		if err := c.checkPatterns(res.doc, pointer, s); err != nil {
+			return err
+		}
+
+		ref, ok := s.Ref()
+		if !ok {
+			return nil
+		}
+
+		target, err := c.resolveRef(base, ref)
+		if err != nil {
+			return fmt.Errorf("Compile Error: $ref %s at %s#%s: %s", ref, res.doc, pointer, err)
+		}
+		if c.compiled[target.doc] {
+			return nil
+		}
+		return c.compile(c.opts.refs.docs[target.doc])
+	})
+}
+
+// resolveRef resolves ref, and the $refs the schema it points to is made of, up
+// to the maximum $ref depth.
+func (c *compiler) resolveRef(base string, ref string) (resource, error) {
+	for depth := 1; ; depth++ {
+		if depth > c.opts.maxRefDepth {
+			return resource{}, fmt.Errorf("more than %d $refs are followed in a row, it may be circular", c.opts.maxRefDepth)
+		}
+
+		target, err := c.opts.refs.resolve(base, ref)
+		if err != nil {
+			return resource{}, err
+		}
+
+		next, ok := target.schema.Ref()
+		if !ok {
+			return target, nil
+		}
+		base, ref = target.base, next
+	}
+}
+
+// checkPatterns checks the pattern and the patternProperties keys of s.
+func (c *compiler) checkPatterns(doc string, pointer string, s Schema) error {
+	var patterns []string
+	if pattern, ok := s.Pattern(); ok {
+		patterns = append(patterns, pattern)
+	}
+	if props, ok := s.PatternProperties(); ok {
+		keys := make([]string, 0, len(props))
+		for pattern := range props {
+			keys = append(keys, pattern)
+		}
+		sort.Strings(keys)
+		patterns = append(patterns, keys...)
+	}
+
+	for _, pattern := range patterns {
+		if max := c.opts.maxPatternLength; max > 0 && len(pattern) > max {
+			return fmt.Errorf("Compile Error: pattern at %s#%s is longer than %d bytes", doc, pointer, max)
+		}
+		if _, err := c.opts.regexps.compile(pattern); err != nil {
+			return fmt.Errorf("Compile Error: invalid pattern at %s#%s: %s", doc, pointer, err)
+		}
+	}
+	return nil
+}
+
+// keywordChecks check the values of the keywords the validation reads. A check
+// returns why the value is invalid, or an empty string.
+var keywordChecks = map[string]func(v interface{}) string{
+	"$ref":                 checkString,
+	"type":                 checkType,
+	"enum":                 checkArray,
+	"allOf":                checkSchemaArray,
+	"anyOf":                checkSchemaArray,
+	"oneOf":                checkSchemaArray,
+	"not":                  checkSchema,
+	"multipleOf":           checkNumber,
+	"maximum":              checkNumber,
+	"minimum":              checkNumber,
+	"exclusiveMaximum":     checkBoolOrNumber,
+	"exclusiveMinimum":     checkBoolOrNumber,
+	"maxLength":            checkCount,
+	"minLength":            checkCount,
+	"pattern":              checkString,
+	"items":                checkItems,
+	"additionalItems":      checkBoolOrSchema,
+	"maxItems":             checkCount,
+	"minItems":             checkCount,
+	"uniqueItems":          checkBool,
+	"maxProperties":        checkCount,
+	"minProperties":        checkCount,
+	"required":             checkStringArray,
+	"properties":           checkSchemaMap,
+	"patternProperties":    checkSchemaMap,
+	"additionalProperties": checkBoolOrSchema,
+}
+
+// checkKeywords checks the values of the keywords of s the validation reads.
+func checkKeywords(doc string, pointer string, s Schema) error {
+	for _, keyword := range sortedKeys(s) {
+		check, ok := keywordChecks[keyword]
+		if !ok {
+			continue
+		}
+		if msg := check(s[keyword]); msg != "" {
+			return fmt.Errorf("Compile Error: invalid %s at %s#%s: %s", keyword, doc, pointer, msg)
+		}
+	}
+	return nil
+}
+
+func checkString(v interface{}) string {
+	if _, ok := v.(string); !ok {
+		return "it must be a string"
+	}
+	return ""
+}
+
+func checkBool(v interface{}) string {
+	if _, ok := v.(bool); !ok {
+		return "it must be a boolean"
+	}
+	return ""
+}
+
+func checkArray(v interface{}) string {
+	if _, ok := v.([]interface{}); !ok {
+		return "it must be an array"
+	}
+	return ""
+}
+
+func checkStringArray(v interface{}) string {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return "it must be an array of strings"
+	}
+	for _, one := range arr {
+		if _, ok := one.(string); !ok {
+			return "it must be an array of strings"
+		}
+	}
+	return ""
+}
+
+// checkNumber checks v is a number, decoded as a json.Number.
+func checkNumber(v interface{}) string {
+	if _, ok := v.(json.Number); !ok {
+		return "it must be a number"
+	}
+	return ""
+}
+
+func checkBoolOrNumber(v interface{}) string {
+	if _, ok := v.(bool); ok {
+		return ""
+	}
+	if checkNumber(v) != "" {
+		return "it must be a boolean or a number"
+	}
+	return ""
+}
+
+// checkCount checks v is a non-negative integer, which may be written with a
+// fraction, such as 1.0.
+func checkCount(v interface{}) string {
+	n, ok := v.(json.Number)
+	if !ok {
+		return "it must be a non-negative integer"
+	}
+	if f, err := n.Float64(); err != nil || f < 0 || f != math.Trunc(f) {
+		return "it must be a non-negative integer"
+	}
+	return ""
+}
+
+func checkType(v interface{}) string {
+	types, ok := v.([]interface{})
+	if !ok {
+		types = []interface{}{v}
+	}
+	for _, t := range types {
+		name, ok := t.(string)
+		if !ok {
+			return "it must be a type name or an array of type names"
+		}
+		switch JsonType(name) {
+		case JsonInteger, JsonNumber, JsonString, JsonArray, JsonObject, JsonBoolean, JsonNull:
+		default:
+			return fmt.Sprintf("%s is not a JSON type", name)
+		}
+	}
+	return ""
+}
+
+func checkSchema(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return ""
+	case bool:
+		return "boolean schemas are not supported"
+	default:
+		return "it must be a schema"
+	}
+}
+
+func checkBoolOrSchema(v interface{}) string {
+	if _, ok := v.(bool); ok {
+		return ""
+	}
+	if checkSchema(v) != "" {
+		return "it must be a boolean or a schema"
+	}
+	return ""
+}
+
+func checkSchemaArray(v interface{}) string {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return "it must be an array of schemas"
+	}
+	for i, one := range arr {
+		if msg := checkSchema(one); msg != "" {
+			return fmt.Sprintf("%d: %s", i, msg)
+		}
+	}
+	return ""
+}
+
+func checkSchemaMap(v interface{}) string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return "it must be an object of schemas"
+	}
+	for _, key := range sortedKeys(Schema(m)) {
+		if msg := checkSchema(m[key]); msg != "" {
+			return fmt.Sprintf("%s: %s", key, msg)
+		}
+	}
+	return ""
+}
+
+// checkItems checks v is a schema or an array of schemas.
+func checkItems(v interface{}) string {
+	if _, ok := v.([]interface{}); ok {
+		return checkSchemaArray(v)
+	}
+	return checkSchema(v)
+}
@@ -0,0 +1,110 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"properties": {
+			"a": {"$ref": "http://example.com/a.json"},
+			"b": {"pattern": "^[a-z]+$"}
+		}
+	}`)
+	assert.NoError(t, err)
+
+	loaded := 0
+	loader := LoaderFunc(func(uri string) (Schema, error) {
+		loaded++
+		return Schema{"$ref": "#/definitions/a", "definitions": map[string]interface{}{
+			"a": map[string]interface{}{"type": "string"},
+		}}, nil
+	})
+
+	validator, err := Compile(s, WithLoader(loader), WithRemoteRefs())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+	assert.False(t, validator.Validate(map[string]interface{}{"a": true}).Valid())
+	assert.Equal(t, 1, loaded)
+
+	tests := []struct {
+		schema string
+		opts   []Option
+		err    string
+	}{
+		{
+			`{"items": {"$ref": "#/definitions/missing"}}`,
+			nil,
+			"Compile Error: $ref #/definitions/missing at #/items: Resolve Error: no definitions in #/definitions/missing",
+		},
+		{
+			`{"definitions": {"a": {"$ref": "#/definitions/b"}, "b": {"$ref": "#/definitions/a"}}, "not": {"$ref": "#/definitions/a"}}`,
+			[]Option{WithMaxRefDepth(5)},
+			"Compile Error: $ref #/definitions/a at #/not: more than 5 $refs are followed in a row, it may be circular",
+		},
+		{
+			`{"$ref": "http://example.com/a.json"}`,
+			nil,
+			"Compile Error: $ref http://example.com/a.json at #: Resolve Error: loading the remote schema http://example.com/a.json is not allowed",
+		},
+		{
+			`{"properties": {"a": {"pattern": "^(a+)+$"}}}`,
+			[]Option{WithMaxPatternLength(5)},
+			"Compile Error: pattern at #/properties/a is longer than 5 bytes",
+		},
+		{
+			`{"patternProperties": {"(": {}}}`,
+			nil,
+			"Compile Error: invalid pattern at #: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			`{"patternProperties": {"a": 1}}`,
+			nil,
+			"Compile Error: invalid patternProperties at #: a: it must be a schema",
+		},
+		{
+			`{"required": "a"}`,
+			nil,
+			"Compile Error: invalid required at #: it must be an array of strings",
+		},
+		{
+			`{"properties": {"a": false}}`,
+			nil,
+			"Compile Error: invalid properties at #: a: boolean schemas are not supported",
+		},
+		{
+			`{"properties": {"a": {"maxLength": "5"}}}`,
+			nil,
+			"Compile Error: invalid maxLength at #/properties/a: it must be a non-negative integer",
+		},
+		{
+			`{"items": [{"type": "string"}, true]}`,
+			nil,
+			"Compile Error: invalid items at #: 1: boolean schemas are not supported",
+		},
+		{
+			`{"allOf": [{"not": {"maximum": true}}]}`,
+			nil,
+			"Compile Error: invalid maximum at #/allOf/0/not: it must be a number",
+		},
+		{
+			`{"type": ["string", "text"]}`,
+			nil,
+			"Compile Error: invalid type at #: text is not a JSON type",
+		},
+	}
+
+	for _, test := range tests {
+		s, err := deserializeSchema(test.schema)
+		assert.NoError(t, err)
+
+		assert.NotPanics(t, func() {
+			validator, err := Compile(s, test.opts...)
+			assert.Nil(t, validator)
+			assert.EqualError(t, err, test.err)
+		}, test.schema)
+	}
+}
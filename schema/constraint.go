@@ -1,6 +1,9 @@
 package schema
 
-import "reflect"
+import (
+	"reflect"
+	"sync/atomic"
+)
 
 type Constraint interface {
 	Errors() []SchemaError
@@ -38,6 +41,23 @@ type Constraint interface {
 type baseConstraint struct {
 	schema Schema
 	errors []SchemaError
+	opts   *options
+
+	// value is the value being validated, and skipped, replaced and replacement
+	// hold the decisions of an interactive validation about it.
+	value       interface{}
+	skipped     bool
+	replaced    bool
+	replacement interface{}
+
+	// base is the base URI the $refs of schema are resolved against, and
+	// refDepth the number of $refs followed since the last value of the
+	// instance was entered.
+	base     string
+	refDepth int
+	// location is the JSON pointer of schema along the evaluation path from the
+	// root schema.
+	location string
 }
 
 func NewBaseConstraint(schema Schema) *baseConstraint {
@@ -46,46 +66,231 @@ func NewBaseConstraint(schema Schema) *baseConstraint {
 	}
 }
 
+// child returns a constraint for a subschema of b, sharing b's options. tokens
+// are the reference tokens of the subschema from the schema of b.
+func (b *baseConstraint) child(schema Schema, tokens ...string) *baseConstraint {
+	c := NewBaseConstraint(schema)
+	c.opts = b.opts
+	c.base = schemaBase(b.base, schema)
+	c.location = b.location + pointer(tokens...)
+	return c
+}
+
+// probe returns a constraint for a subschema of b whose errors are only used to
+// tell whether v is valid, such as the subschemas of allOf, anyOf, oneOf and not.
+// It stops at its first error.
+func (b *baseConstraint) probe(schema Schema) *baseConstraint {
+	o := options{}
+	if b.opts != nil {
+		o = *b.opts
+	}
+	o.failFast = true
+	o.probing = true
+	// a probe stops at its own first error, not at the ones of the validation
+	o.errorCount = new(int64)
+
+	c := NewBaseConstraint(schema)
+	c.opts = &o
+	c.base = schemaBase(b.base, schema)
+	c.refDepth = b.refDepth
+	return c
+}
+
+// stopped reports whether b must not evaluate more keywords, because it is done,
+// the validation was aborted, canceled or exhausted, or its value was skipped or
+// replaced.
+func (b *baseConstraint) stopped() bool {
+	return b.done() || b.aborted() || b.canceled() || b.exhausted() || b.skipped || b.replaced
+}
+
+// done reports whether b must not collect more errors, because of the fail-fast
+// or maximum errors options. The errors are counted for the whole validation, so
+// the children of b and the workers of a parallel validation stop as well.
+func (b *baseConstraint) done() bool {
+	if b.opts == nil {
+		return false
+	}
+	n := len(b.errors)
+	if b.opts.errorCount != nil {
+		n = int(atomic.LoadInt64(b.opts.errorCount))
+	}
+	return b.full(n)
+}
+
+// full reports whether n errors are all the fail-fast or maximum errors options
+// allow.
+func (b *baseConstraint) full(n int) bool {
+	if b.opts.failFast && n > 0 {
+		return true
+	}
+	return b.opts.maxErrors > 0 && n >= b.opts.maxErrors
+}
+
+// countErrors adds n to the errors of the validation.
+func (b *baseConstraint) countErrors(n int) {
+	if b.opts != nil && b.opts.errorCount != nil {
+		atomic.AddInt64(b.opts.errorCount, int64(n))
+	}
+}
+
 func (b *baseConstraint) Errors() []SchemaError {
 	return b.errors
 }
 
 func (b *baseConstraint) addError(e SchemaError) {
+	if b.stopped() {
+		return
+	}
+	if !b.resolve(e) {
+		return
+	}
+	b.collect(e)
+}
+
+// collect adds e without asking the resolver of an interactive validation.
+func (b *baseConstraint) collect(e SchemaError) {
 	b.errors = append(b.errors, e)
+	b.countErrors(1)
+	b.locate(e)
+	b.emit(ErrorEvent, e.Path(), errorKeyword(e.Code()), e.Code())
 }
 
+// addErrors adds the errors of a child of b, which were counted when they were
+// raised.
 func (b *baseConstraint) addErrors(e []SchemaError) {
-	b.errors = append(b.errors, e...)
+	for _, one := range e {
+		if b.opts != nil && b.full(len(b.errors)) {
+			return
+		}
+		b.errors = append(b.errors, one)
+	}
 }
 
 func (b *baseConstraint) Validate(v interface{}, path string) {
-	b.validateType(v, path)
-	b.validateEnum(v, path)
-	b.validateAllOf(v, path)
-	b.validateAnyOf(v, path)
-	b.validateOneOf(v, path)
-	b.validateNot(v, path)
+	start := len(b.errors)
+	replaced := false
+	for n := 0; ; n++ {
+		b.value, b.skipped, b.replaced, b.replacement = v, false, false, nil
+		if !b.evaluate() {
+			break
+		}
+
+		b.validateValue(v, path)
+		if !b.replaced {
+			break
+		}
+
+		// the value was replaced interactively, the errors of the old value are
+		// dropped and the new one is validated instead, unless it can not be
+		b.replaced = false
+		if n >= maxReplacements {
+			b.collect(newError(MaxReplacementsError, path).withParam("limit", maxReplacements))
+			break
+		}
+		replacement, err := jsonValue(b.replacement)
+		if err != nil {
+			b.collect(newError(InvalidReplacementError, path).withParam("error", err.Error()))
+			break
+		}
+		b.countErrors(start - len(b.errors))
+		b.errors = b.errors[:start]
+		v, replaced = replacement, true
+	}
+
+	if replaced {
+		b.replaced, b.replacement = true, v
+	}
+}
+
+func (b *baseConstraint) validateValue(v interface{}, path string) {
+	if b.opts != nil && b.opts.errorMessages {
+		defer b.applyErrorMessages(v, len(b.errors))
+	}
+
+	b.emit(SchemaEnteredEvent, path, "", "")
+	defer b.emit(SchemaExitedEvent, path, "", "")
+
+	if ref, ok := b.schema.Ref(); ok {
+		b.validateRef(ref, v, path)
+		b.evaluated(path, "$ref")
+		return
+	}
+
+	// the coerced value is validated instead, as a replaced one
+	if coerced, ok := b.coerce(v); ok {
+		b.replaced, b.replacement = true, coerced
+		return
+	}
+
+	validations := []struct {
+		keyword  string
+		validate func(interface{}, string)
+	}{
+		{"type", b.validateType},
+		{"enum", b.validateEnum},
+		{"allOf", b.validateAllOf},
+		{"anyOf", b.validateAnyOf},
+		{"oneOf", b.validateOneOf},
+		{"not", b.validateNot},
+	}
+	for _, validation := range validations {
+		if b.stopped() {
+			return
+		}
+		if _, ok := b.schema[validation.keyword]; !ok {
+			continue
+		}
+		validation.validate(v, path)
+		b.evaluated(path, validation.keyword)
+	}
+	if b.stopped() {
+		return
+	}
 
 	t, err := getJsonType(v)
 	if err != nil {
 		b.addError(newError(UndefinedTypeError, path))
+		return
 	}
 
-	var c Constraint
+	// booleans and null have no keywords of their own
+	c, cb := b.typeConstraint(t)
+	if c == nil {
+		return
+	}
+
+	cb.value, cb.base, cb.refDepth, cb.location = v, b.base, b.refDepth, b.location
+	c.Validate(v, path)
+	b.addErrors(c.Errors())
+
+	if cb.replaced {
+		b.replaced, b.replacement = true, cb.replacement
+	}
+}
+
+// typeConstraint returns the constraint for the keywords specific to json type t
+// and its embedded baseConstraint, or nil if there is none.
+func (b *baseConstraint) typeConstraint(t JsonType) (Constraint, *baseConstraint) {
 	switch t {
 	case JsonInteger, JsonNumber:
-		c = NewNumericConstraint(b.schema)
+		c := NewNumericConstraint(b.schema)
+		c.opts = b.opts
+		return c, &c.baseConstraint
 	case JsonString:
-		c = NewStringConstraint(b.schema)
+		c := NewStringConstraint(b.schema)
+		c.opts = b.opts
+		return c, &c.baseConstraint
 	case JsonArray:
-		c = NewArrayConstraint(b.schema)
+		c := NewArrayConstraint(b.schema)
+		c.opts = b.opts
+		return c, &c.baseConstraint
+	case JsonObject:
+		c := NewObjectConstraint(b.schema)
+		c.opts = b.opts
+		return c, &c.baseConstraint
 	default:
-		b.addError(newError(UndefinedTypeError, path))
-		return
+		return nil, nil
 	}
-
-	c.Validate(v, path)
-	b.addErrors(c.Errors())
 }
 
 func (b *baseConstraint) validateType(v interface{}, path string) {
@@ -99,21 +304,26 @@ func (b *baseConstraint) validateType(v interface{}, path string) {
 		return
 	}
 
+	// an integer is a number too
+	matches := func(t JsonType) bool {
+		return t == actualType || (t == JsonNumber && actualType == JsonInteger)
+	}
+
 	// single type
 	if expectedType != "" {
-		if expectedType != actualType {
-			b.addError(newError(TypeNotMatchError, path))
+		if !matches(expectedType) {
+			b.addError(newError(TypeNotMatchError, path).withParam("expected", expectedType))
 		}
 		return
 	}
 
 	// mixed type
 	for _, t := range expectedTypes {
-		if t == actualType {
+		if matches(t) {
 			return
 		}
 	}
-	b.addError(newError(TypesNotMatchError, path))
+	b.addError(newError(TypesNotMatchError, path).withParam("expected", expectedTypes))
 }
 
 func (b *baseConstraint) validateEnum(v interface{}, path string) {
@@ -137,7 +347,11 @@ func (b *baseConstraint) validateAllOf(v interface{}, path string) {
 	}
 
 	for _, one := range all {
-		c := NewBaseConstraint(one)
+		if b.stopped() {
+			return
+		}
+
+		c := b.probe(one)
 		c.Validate(v, path)
 
 		if len(c.Errors()) > 0 {
@@ -153,7 +367,7 @@ func (b *baseConstraint) validateAnyOf(v interface{}, path string) {
 	}
 
 	for _, one := range any {
-		c := NewBaseConstraint(one)
+		c := b.probe(one)
 		c.Validate(v, path)
 
 		if len(c.Errors()) == 0 {
@@ -172,12 +386,17 @@ func (b *baseConstraint) validateOneOf(v interface{}, path string) {
 
 	i := 0
 	for _, one := range all {
-		c := NewBaseConstraint(one)
+		c := b.probe(one)
 		c.Validate(v, path)
 
 		if len(c.Errors()) == 0 {
 			i++
 		}
+
+		// more than one match can not become valid again
+		if i > 1 {
+			break
+		}
 	}
 
 	if i != 1 {
@@ -191,7 +410,7 @@ func (b *baseConstraint) validateNot(v interface{}, path string) {
 		return
 	}
 
-	c := NewBaseConstraint(not)
+	c := b.probe(not)
 	c.Validate(v, path)
 	if len(c.Errors()) == 0 {
 		b.addError(newError(NotError, path))
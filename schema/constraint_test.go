@@ -38,7 +38,7 @@ func TestTypeConstraint(t *testing.T) {
 			},
 			value: "str",
 			expected: []SchemaError{
-				newError(TypesNotMatchError, "a"),
+				newError(TypesNotMatchError, "a").withParam("expected", []JsonType{JsonInteger}),
 			},
 		},
 		{
@@ -47,7 +47,30 @@ func TestTypeConstraint(t *testing.T) {
 			},
 			value: "str",
 			expected: []SchemaError{
-				newError(TypeNotMatchError, "a"),
+				newError(TypeNotMatchError, "a").withParam("expected", JsonObject),
+			},
+		},
+		{
+			schema: Schema{
+				"type": "number",
+			},
+			value:    json.Number("5"),
+			expected: nil,
+		},
+		{
+			schema: Schema{
+				"type": []interface{}{"string", "number"},
+			},
+			value:    json.Number("5"),
+			expected: nil,
+		},
+		{
+			schema: Schema{
+				"type": "integer",
+			},
+			value: json.Number("5.5"),
+			expected: []SchemaError{
+				newError(TypeNotMatchError, "a").withParam("expected", JsonInteger),
 			},
 		},
 	}
@@ -333,4 +356,90 @@ func TestBaseConstraint(t *testing.T) {
 		c.Validate(test.value, "a")
 		assert.Equal(t, test.expected, c.Errors())
 	}
-}
\ No newline at end of file
+}
+
+func TestFailFastAndMaxErrors(t *testing.T) {
+	s := Schema{
+		"type": "string",
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": "integer"},
+			map[string]interface{}{"type": "boolean"},
+		},
+		"allOf": []interface{}{
+			map[string]interface{}{"type": "integer"},
+			map[string]interface{}{"type": "boolean"},
+		},
+		"items": map[string]interface{}{
+			"type":    "string",
+			"maximum": json.Number("1"),
+		},
+	}
+	value := []interface{}{json.Number("2"), json.Number("2"), json.Number("2")}
+
+	tests := []struct {
+		opts     []Option
+		expected []ErrorCode
+	}{
+		{
+			opts: nil,
+			expected: []ErrorCode{
+				TypeNotMatchError,
+				AllOfError,
+				AllOfError,
+				AnyOfError,
+				TypeNotMatchError,
+				NumericMaximumError,
+				TypeNotMatchError,
+				NumericMaximumError,
+				TypeNotMatchError,
+				NumericMaximumError,
+			},
+		},
+		{
+			opts:     []Option{WithFailFast()},
+			expected: []ErrorCode{TypeNotMatchError},
+		},
+		{
+			opts:     []Option{WithMaxErrors(3)},
+			expected: []ErrorCode{TypeNotMatchError, AllOfError, AllOfError},
+		},
+		{
+			opts: []Option{WithMaxErrors(100)},
+			expected: []ErrorCode{
+				TypeNotMatchError,
+				AllOfError,
+				AllOfError,
+				AnyOfError,
+				TypeNotMatchError,
+				NumericMaximumError,
+				TypeNotMatchError,
+				NumericMaximumError,
+				TypeNotMatchError,
+				NumericMaximumError,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		res := NewValidator(s, test.opts...).Validate(value)
+
+		var codes []ErrorCode
+		for _, e := range res.Errors {
+			codes = append(codes, e.Code())
+		}
+		assert.Equal(t, test.expected, codes)
+	}
+}
+
+func TestFailFastItems(t *testing.T) {
+	s := Schema{
+		"items": map[string]interface{}{
+			"type": "string",
+		},
+	}
+	value := []interface{}{"a", json.Number("1"), json.Number("2")}
+
+	res := NewValidator(s, WithFailFast()).Validate(value)
+	assert.Len(t, res.Errors, 1)
+	assert.Equal(t, "$[1]", res.Errors[0].Path())
+}
@@ -0,0 +1,40 @@
+package schema
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ValidateContext validates v like Validate, but stops as soon as ctx is done.
+// The result then holds the errors found so far, and a ValidationDeadlineError
+// if the deadline of ctx was exceeded or a ValidationCanceledError if ctx was
+// canceled.
+func (validator *Validator) ValidateContext(ctx context.Context, v interface{}, opts ...Option) *Result {
+	return validator.Validate(v, append(opts, WithContext(ctx))...)
+}
+
+// canceled reports whether the context of the validation is done.
+func (b *baseConstraint) canceled() bool {
+	if b.opts == nil || b.opts.ctx == nil || b.opts.ctx.Err() == nil {
+		return false
+	}
+
+	if b.opts.interrupted != nil {
+		atomic.StoreInt32(b.opts.interrupted, 1)
+	}
+	return true
+}
+
+// addContextError adds the error telling that the validation was stopped by its
+// context, if it was.
+func (b *baseConstraint) addContextError(path string) {
+	if b.opts == nil || b.opts.interrupted == nil || atomic.LoadInt32(b.opts.interrupted) == 0 {
+		return
+	}
+
+	code := ValidationCanceledError
+	if b.opts.ctx.Err() == context.DeadlineExceeded {
+		code = ValidationDeadlineError
+	}
+	b.errors = append(b.errors, newError(code, path))
+}
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cancelOnError struct {
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnError) Emit(name string, path string, keyword string, code ErrorCode) {
+	if name == ErrorEvent {
+		c.cancel()
+	}
+}
+
+func TestValidateContext(t *testing.T) {
+	s := Schema{
+		"items": map[string]interface{}{
+			"type": "integer",
+		},
+	}
+	items := []interface{}{json.Number("1"), "a", "b", "c"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res := NewValidator(s, WithEventEmitter(&cancelOnError{cancel})).ValidateContext(ctx, items)
+	assert.Equal(t, []SchemaError{
+		newError(TypeNotMatchError, "$[1]").withParam("expected", JsonInteger),
+		newError(ValidationCanceledError, "$"),
+	}, res.Errors)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	res = NewValidator(s).ValidateContext(ctx, items)
+	assert.Equal(t, []SchemaError{newError(ValidationDeadlineError, "$")}, res.Errors)
+
+	res, err := NewValidator(s).ValidateReader(strings.NewReader(`[1, "a"]`), WithContext(ctx))
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaError{newError(ValidationDeadlineError, "$")}, res.Errors)
+
+	// a context that is not done does not change the result
+	res = NewValidator(s).ValidateContext(context.Background(), items)
+	assert.Len(t, res.Errors, 3)
+}
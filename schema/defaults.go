@@ -0,0 +1,67 @@
+package schema
+
+// applyDefaults sets the missing properties of obj that have a default value in
+// "properties", following the $refs to their schema. The subschemas of allOf,
+// anyOf, oneOf and not do not apply defaults, as they may not match.
+func (o *ObjectConstraint) applyDefaults(obj map[string]interface{}) {
+	if o.opts == nil || !o.opts.defaults || o.opts.probing {
+		return
+	}
+
+	props, ok := o.schema.Properties()
+	if !ok {
+		return
+	}
+
+	for prop, s := range props {
+		if _, ok := obj[prop]; ok {
+			continue
+		}
+		if v, ok := o.defaultValue(s); ok {
+			obj[prop] = copyValue(v)
+		}
+	}
+}
+
+// defaultValue returns the default value of s, or of the schema its $ref points
+// to.
+func (o *ObjectConstraint) defaultValue(s Schema) (interface{}, bool) {
+	base := schemaBase(o.base, s)
+	for depth := 0; ; depth++ {
+		if v, ok := s["default"]; ok {
+			return v, true
+		}
+
+		ref, ok := s.Ref()
+		if !ok || o.opts.refs == nil || depth >= o.opts.maxRefDepth {
+			return nil, false
+		}
+		res, err := o.opts.refs.resolve(base, ref)
+		if err != nil {
+			// reported when the property is validated
+			return nil, false
+		}
+		s, base = res.schema, res.base
+	}
+}
+
+// copyValue returns a deep copy of the JSON value v, so that the defaults of the
+// schema are not modified through the instances.
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			obj[key] = copyValue(item)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = copyValue(item)
+		}
+		return arr
+	default:
+		return v
+	}
+}
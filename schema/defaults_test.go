@@ -0,0 +1,79 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaults(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"type": "object",
+		"properties": {
+			"port": {"type": "integer", "default": 8080},
+			"tls": {
+				"type": "object",
+				"default": {},
+				"properties": {
+					"enabled": {"default": false},
+					"ciphers": {"default": ["a", "b"]}
+				}
+			},
+			"backends": {
+				"type": "array",
+				"items": {"$ref": "#/definitions/backend"}
+			},
+			"log": {"$ref": "#/definitions/log"}
+		},
+		"anyOf": [
+			{"properties": {"unused": {"default": 1}}}
+		],
+		"definitions": {
+			"backend": {
+				"type": "object",
+				"required": ["host", "weight"],
+				"properties": {
+					"host": {"type": "string"},
+					"weight": {"type": "integer", "default": 1}
+				}
+			},
+			"log": {"type": "string", "default": "info"}
+		}
+	}`)
+	assert.NoError(t, err)
+
+	v, err := deserializeValue(`
+	{
+		"port": 9090,
+		"backends": [{"host": "a"}, {"host": "b", "weight": 3}]
+	}`)
+	assert.NoError(t, err)
+
+	expected, err := deserializeValue(`
+	{
+		"port": 9090,
+		"tls": {"enabled": false, "ciphers": ["a", "b"]},
+		"backends": [{"host": "a", "weight": 1}, {"host": "b", "weight": 3}],
+		"log": "info"
+	}`)
+	assert.NoError(t, err)
+
+	res := NewValidator(s, WithDefaults()).Validate(v)
+	assert.True(t, res.Valid())
+	assert.Equal(t, expected, res.Value)
+
+	// the defaults of the schema are copied
+	res.Value.(map[string]interface{})["tls"].(map[string]interface{})["ciphers"].([]interface{})[0] = "c"
+	assert.Equal(t, []interface{}{"a", "b"}, s["properties"].(map[string]interface{})["tls"].(map[string]interface{})["properties"].(map[string]interface{})["ciphers"].(map[string]interface{})["default"])
+
+	// without the option, the instance is not changed and the required weight
+	// is missing
+	v, err = deserializeValue(`{"backends": [{"host": "a"}]}`)
+	assert.NoError(t, err)
+	res = NewValidator(s).Validate(v)
+	assert.Equal(t, []SchemaError{
+		newError(ObjectRequiredPropertiesError, "$.backends[0]").withParam("property", "weight"),
+	}, res.Errors)
+	assert.Equal(t, map[string]interface{}{"backends": []interface{}{map[string]interface{}{"host": "a"}}}, res.Value)
+}
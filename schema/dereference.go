@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Dereference returns a copy of s whose $refs are replaced by a copy of the
+// schema they point to, the keywords next to a $ref being ignored as by the
+// validation. Dereference fails on a $ref it can not resolve, such as one to a
+// document neither given with WithSchema nor allowed to be loaded by
+// WithRemoteRefs or WithFileRefs. A $ref to a schema being expanded is cyclic,
+// it is kept with its URI made absolute, unless WithoutCyclicRefs is given, in
+// which case Dereference returns an error. When s itself is a $ref, its
+// $schema, $id and definitions are kept, so that the cyclic $refs still resolve.
+//
+// A schema $referenced from several places is copied at each of them, so the
+// copy can grow exponentially with the nesting of such $refs. Dereference
+// returns an error once the copy holds more subschemas than the maximum
+// evaluations option, or DefaultMaxDereferencedSchemas if it is not given.
+func Dereference(s Schema, opts ...Option) (Schema, error) {
+	validator := NewValidator(s, opts...)
+	d := &dereferencer{opts: validator.options(nil), max: DefaultMaxDereferencedSchemas}
+	if d.opts.maxEvaluations > 0 {
+		d.max = d.opts.maxEvaluations
+	}
+
+	deref, err := d.dereference(s, "", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.Ref(); ok {
+		for _, keyword := range []string{"$schema", "$id", "id", "$defs", "definitions"} {
+			v, ok := s[keyword]
+			if _, exist := deref[keyword]; exist || !ok {
+				continue
+			}
+			defs, ok := v.(map[string]interface{})
+			if !ok || keyword == "$schema" || keyword == "$id" || keyword == "id" {
+				deref[keyword] = copyValue(v)
+				continue
+			}
+			if deref[keyword], err = d.dereferenceMap(defs, "/"+keyword, schemaBase("", s), nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return deref, nil
+}
+
+// DefaultMaxDereferencedSchemas is the number of subschemas from which
+// Dereference fails, when WithMaxEvaluations is not given.
+const DefaultMaxDereferencedSchemas = 100000
+
+type dereferencer struct {
+	opts *options
+	// count is the number of subschemas copied so far, and max its limit.
+	count int
+	max   int
+}
+
+// dereference returns the dereferenced copy of s at pointer, whose parent's base
+// URI is base. expanding holds the schemas being expanded.
+func (d *dereferencer) dereference(s Schema, pointer string, base string, expanding []uintptr) (Schema, error) {
+	base = schemaBase(base, s)
+	expanding = append(expanding[:len(expanding):len(expanding)], reflect.ValueOf(s).Pointer())
+
+	if ref, ok := s.Ref(); ok {
+		target, err := d.opts.refs.resolve(base, ref)
+		if err != nil {
+			return nil, fmt.Errorf("Dereference Error: $ref %s at #%s: %s", ref, pointer, err)
+		}
+
+		id := reflect.ValueOf(target.schema).Pointer()
+		for _, one := range expanding {
+			if one != id {
+				continue
+			}
+			if d.opts.noCyclicRefs {
+				return nil, fmt.Errorf("Dereference Error: $ref %s at #%s is cyclic", ref, pointer)
+			}
+			cyclic := copyValue(map[string]interface{}(s)).(map[string]interface{})
+			cyclic["$ref"] = resolveURI(base, ref)
+			return Schema(cyclic), nil
+		}
+
+		return d.dereference(target.schema, pointer, target.base, expanding)
+	}
+
+	if d.count++; d.count > d.max {
+		return nil, fmt.Errorf("Dereference Error: the dereferenced schema has more than %d subschemas", d.max)
+	}
+
+	deref := make(Schema, len(s))
+	for keyword, v := range s {
+		deref[keyword] = copyValue(v)
+	}
+
+	for _, keyword := range schemaKeywords {
+		if sub, ok := s[keyword].(map[string]interface{}); ok {
+			one, err := d.dereference(Schema(sub), pointer+"/"+keyword, base, expanding)
+			if err != nil {
+				return nil, err
+			}
+			deref[keyword] = map[string]interface{}(one)
+		}
+	}
+
+	for _, keyword := range arraySchemaKeywords {
+		subs, ok := s[keyword].([]interface{})
+		if !ok {
+			continue
+		}
+		arr := make([]interface{}, len(subs))
+		for i, sub := range subs {
+			arr[i] = copyValue(sub)
+			if sub, ok := sub.(map[string]interface{}); ok {
+				one, err := d.dereference(Schema(sub), fmt.Sprintf("%s/%s/%d", pointer, keyword, i), base, expanding)
+				if err != nil {
+					return nil, err
+				}
+				arr[i] = map[string]interface{}(one)
+			}
+		}
+		deref[keyword] = arr
+	}
+
+	for _, keyword := range mapSchemaKeywords {
+		if subs, ok := s[keyword].(map[string]interface{}); ok {
+			obj, err := d.dereferenceMap(subs, pointer+"/"+keyword, base, expanding)
+			if err != nil {
+				return nil, err
+			}
+			deref[keyword] = obj
+		}
+	}
+
+	return deref, nil
+}
+
+// dereferenceMap returns the dereferenced copy of the object of schemas subs.
+func (d *dereferencer) dereferenceMap(subs map[string]interface{}, pointer string, base string, expanding []uintptr) (map[string]interface{}, error) {
+	obj := make(map[string]interface{}, len(subs))
+	// sorted, so that the first error is stable
+	for _, name := range sortedKeys(Schema(subs)) {
+		obj[name] = copyValue(subs[name])
+		// dependencies also holds arrays of property names
+		if sub, ok := subs[name].(map[string]interface{}); ok {
+			one, err := d.dereference(Schema(sub), pointer+"/"+escapePointer(name), base, expanding)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = map[string]interface{}(one)
+		}
+	}
+	return obj, nil
+}
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDereference(t *testing.T) {
+	tests := []struct {
+		schema   string
+		expected string
+	}{
+		{
+			schema: `{
+				"properties": {
+					"name": {"$ref": "#/definitions/name", "description": "ignored"},
+					"tags": {"items": {"$ref": "#/definitions/alias"}},
+					"owner": {"$ref": "http://example.com/person.json"}
+				},
+				"definitions": {
+					"name": {"type": "string"},
+					"alias": {"$ref": "#/definitions/name"}
+				}
+			}`,
+			expected: `{
+				"properties": {
+					"name": {"type": "string"},
+					"tags": {"items": {"type": "string"}},
+					"owner": {"$id": "http://example.com/person.json", "properties": {"name": {"type": "integer"}}}
+				},
+				"definitions": {
+					"name": {"type": "string"},
+					"alias": {"type": "string"}
+				}
+			}`,
+		},
+		{
+			// the cyclic $refs are kept
+			schema: `{
+				"$id": "http://example.com/tree.json",
+				"properties": {"root": {"$ref": "#/definitions/node"}},
+				"definitions": {
+					"node": {"properties": {"children": {"items": {"$ref": "#/definitions/node"}}}}
+				}
+			}`,
+			expected: `{
+				"$id": "http://example.com/tree.json",
+				"properties": {
+					"root": {"properties": {"children": {"items": {"$ref": "http://example.com/tree.json#/definitions/node"}}}}
+				},
+				"definitions": {
+					"node": {"properties": {"children": {"items": {"$ref": "http://example.com/tree.json#/definitions/node"}}}}
+				}
+			}`,
+		},
+		{
+			// the root keeps its definitions
+			schema: `{
+				"$ref": "#/definitions/list",
+				"definitions": {
+					"list": {"anyOf": [{"type": "null"}, {"properties": {"next": {"$ref": "#/definitions/list"}}}]}
+				}
+			}`,
+			expected: `{
+				"anyOf": [{"type": "null"}, {"properties": {"next": {"$ref": "#/definitions/list"}}}],
+				"definitions": {
+					"list": {"anyOf": [{"type": "null"}, {"properties": {"next": {"$ref": "#/definitions/list"}}}]}
+				}
+			}`,
+		},
+	}
+
+	person := Schema{"$id": "http://example.com/person.json", "properties": map[string]interface{}{
+		"name": map[string]interface{}{"type": "integer"},
+	}}
+	for _, test := range tests {
+		s, err := deserializeSchema(test.schema)
+		assert.NoError(t, err)
+		expected, err := deserializeSchema(test.expected)
+		assert.NoError(t, err)
+
+		deref, err := Dereference(s, WithSchema("http://example.com/person.json", person))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, deref, test.schema)
+
+		// the input is not changed
+		again, err := deserializeSchema(test.schema)
+		assert.NoError(t, err)
+		assert.Equal(t, again, s)
+	}
+}
+
+func TestDereferenceError(t *testing.T) {
+	s, err := deserializeSchema(`{"properties": {"a": {"$ref": "#"}, "b": {"$ref": "#/definitions/missing"}}}`)
+	assert.NoError(t, err)
+
+	_, err = Dereference(s, WithoutCyclicRefs())
+	assert.EqualError(t, err, "Dereference Error: $ref # at #/properties/a is cyclic")
+
+	_, err = Dereference(s)
+	assert.EqualError(t, err, "Dereference Error: $ref #/definitions/missing at #/properties/b: Resolve Error: no definitions in #/definitions/missing")
+}
+
+func TestDereferenceLimit(t *testing.T) {
+	// every definition references the previous one twice, so the copy doubles at
+	// each level
+	chain := func(n int) Schema {
+		defs := map[string]interface{}{"d0": map[string]interface{}{"type": "string"}}
+		for i := 1; i <= n; i++ {
+			prev := map[string]interface{}{"$ref": fmt.Sprintf("#/definitions/d%d", i-1)}
+			defs[fmt.Sprintf("d%d", i)] = map[string]interface{}{"allOf": []interface{}{prev, prev}}
+		}
+		return Schema{"properties": map[string]interface{}{"a": map[string]interface{}{"$ref": fmt.Sprintf("#/definitions/d%d", n)}}, "definitions": defs}
+	}
+
+	_, err := Dereference(chain(40))
+	assert.EqualError(t, err, "Dereference Error: the dereferenced schema has more than 100000 subschemas")
+
+	_, err = Dereference(chain(40), WithMaxEvaluations(1000))
+	assert.EqualError(t, err, "Dereference Error: the dereferenced schema has more than 1000 subschemas")
+
+	// 2^4 copies of d0 under a, and the definitions
+	_, err = Dereference(chain(4), WithMaxEvaluations(100))
+	assert.NoError(t, err)
+}
@@ -16,6 +16,8 @@ const (
 	StringMinLengthError    = ErrorCode("minLength")
 	StringMaxLengthError    = ErrorCode("maxLength")
 	StringPatternError      = ErrorCode("pattern")
+	InvalidPatternError     = ErrorCode("invalid pattern")
+	StringInvalidUTF8Error  = ErrorCode("invalid utf8")
 
 	ArrayTypeMismatchError   = ErrorCode("array type")
 	ArrayMaxItemError        = ErrorCode("maxItems")
@@ -28,7 +30,7 @@ const (
 	ObjectMaxPropertiesError      = ErrorCode("max properties")
 	ObjectMinPropertiesError      = ErrorCode("min properties")
 	ObjectRequiredPropertiesError = ErrorCode("required properties")
-	ObjectUndefinedPropertyError = ErrorCode("undifined property")
+	ObjectUndefinedPropertyError  = ErrorCode("undifined property")
 
 	TypeError          = ErrorCode("type")
 	TypeNotMatchError  = ErrorCode("not match type")
@@ -42,21 +44,56 @@ const (
 	NotError   = ErrorCode("not")
 
 	UndefinedTypeError = ErrorCode("undefined type")
+
+	InvalidJSONError = ErrorCode("invalid json")
+
+	ValidationCanceledError = ErrorCode("canceled")
+	ValidationDeadlineError = ErrorCode("deadline exceeded")
+
+	RefError            = ErrorCode("$ref")
+	MaxRefDepthError    = ErrorCode("max $ref depth")
+	MaxDepthError       = ErrorCode("max depth")
+	MaxEvaluationsError = ErrorCode("max evaluations")
+
+	InvalidReplacementError = ErrorCode("invalid replacement")
+	MaxReplacementsError    = ErrorCode("max replacements")
 )
 
 type SchemaError interface {
 	error
 	Code() ErrorCode
 	Path() string
+	// Params returns the values a message template can refer to, such as the
+	// "limit" of a maxLength error. It may be nil.
+	Params() map[string]interface{}
+	// Message returns the message declared for the error with the errorMessage
+	// keyword, or an empty string.
+	Message() string
 }
 
 type schemaError struct {
-	code ErrorCode
-	path string
+	code   ErrorCode
+	path   string
+	params map[string]interface{}
+
+	// message is the custom message from the errorMessage keyword, and scoped
+	// is set once the subschema that raised the error has been checked for one.
+	message string
+	scoped  bool
 }
 
 func newError(code ErrorCode, path string) *schemaError {
-	return &schemaError{code, path}
+	return &schemaError{code: code, path: path}
+}
+
+// withParam records a template parameter on the error and returns it, so it can
+// be chained directly on newError.
+func (s *schemaError) withParam(key string, value interface{}) *schemaError {
+	if s.params == nil {
+		s.params = make(map[string]interface{})
+	}
+	s.params[key] = value
+	return s
 }
 
 func (s *schemaError) Code() ErrorCode {
@@ -67,6 +104,14 @@ func (s *schemaError) Path() string {
 	return s.path
 }
 
+func (s *schemaError) Params() map[string]interface{} {
+	return s.params
+}
+
+func (s *schemaError) Message() string {
+	return s.message
+}
+
 func (s *schemaError) Error() string {
 	return fmt.Sprintf("Error: %s, Path: %s", s.Code(), s.Path())
 }
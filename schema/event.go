@@ -0,0 +1,54 @@
+package schema
+
+// Names of the events raised during a validation.
+const (
+	// SchemaEnteredEvent is raised before a (sub)schema validates a value.
+	SchemaEnteredEvent = "schema.entered"
+	// SchemaExitedEvent is raised after a (sub)schema validated a value.
+	SchemaExitedEvent = "schema.exited"
+	// KeywordEvaluatedEvent is raised after a keyword of a schema was evaluated.
+	KeywordEvaluatedEvent = "keyword.evaluated"
+	// ErrorEvent is raised for every error collected by the validation. Errors of
+	// the subschemas of allOf, anyOf, oneOf and not are not raised, as they only
+	// decide whether the composition keyword itself fails.
+	ErrorEvent = "error"
+)
+
+// EventEmitter receives the events raised during a validation. The event package
+// provides an implementation that dispatches them to subscribed handlers.
+type EventEmitter interface {
+	Emit(name string, path string, keyword string, code ErrorCode)
+}
+
+func (b *baseConstraint) emit(name string, path string, keyword string, code ErrorCode) {
+	if b.opts == nil || b.opts.emitter == nil {
+		return
+	}
+	if name == ErrorEvent && b.opts.probing {
+		return
+	}
+	b.opts.emitter.Emit(name, path, keyword, code)
+}
+
+// evaluated raises the KeywordEvaluatedEvent for keyword.
+func (b *baseConstraint) evaluated(path string, keyword string) {
+	b.emit(KeywordEvaluatedEvent, path, keyword, "")
+}
+
+// Keyword returns the keyword that raises the errors with code c, or an empty
+// string for the errors that are not raised by a keyword.
+func (c ErrorCode) Keyword() string {
+	return errorKeyword(c)
+}
+
+// errorKeyword returns the keyword that raises errors with code.
+func errorKeyword(code ErrorCode) string {
+	for keyword, codes := range keywordErrorCodes {
+		for _, c := range codes {
+			if c == code {
+				return keyword
+			}
+		}
+	}
+	return ""
+}
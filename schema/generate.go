@@ -0,0 +1,734 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+// The limits of the generation: the attempts to generate a valid value for a
+// subschema, the nesting of the generated values, and the sizes used when the
+// schema does not bound them.
+const (
+	generateAttempts = 20
+	maxGenerateDepth = 32
+	// past optionalDepth, only the required properties and items are generated,
+	// so that the recursive schemas end
+	optionalDepth    = 4
+	defaultSpan      = 100
+	defaultLength    = 8
+	defaultItems     = 3
+	maxPatternRepeat = 3
+)
+
+// Generate returns a random value valid against s, drawn from src. It follows
+// type, enum and const, the numeric bounds and multipleOf, the lengths,
+// pattern for the regular expressions without lookarounds or backreferences,
+// required, properties, items, $ref, and allOf, anyOf and oneOf. Other keywords
+// are satisfied by generating values again until one is valid. A $ref is
+// generated from the schema it points to, and one that can not be resolved is
+// an error.
+func Generate(s Schema, src rand.Source, opts ...Option) (interface{}, error) {
+	g := newGenerator(s, src, opts)
+	return g.generate(g.root, "", 0)
+}
+
+// GenerateInvalid returns a random value invalid against s, drawn from src. The
+// value is a valid one, as returned by Generate, with one change breaking one
+// keyword, such as a number past its maximum or a required property removed.
+func GenerateInvalid(s Schema, src rand.Source, opts ...Option) (interface{}, error) {
+	g := newGenerator(s, src, opts)
+	v, err := g.generate(g.root, "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	mutations, err := g.mutations(g.root, v, nil)
+	if err != nil {
+		return nil, err
+	}
+	g.rnd.Shuffle(len(mutations), func(i, j int) {
+		mutations[i], mutations[j] = mutations[j], mutations[i]
+	})
+	for _, m := range mutations {
+		if invalid := m.apply(v); !g.root.accepts(invalid) {
+			return invalid, nil
+		}
+	}
+	return nil, fmt.Errorf("Generate Error: no invalid value found, the schema may accept any value")
+}
+
+type generator struct {
+	rnd  *rand.Rand
+	root scope
+}
+
+func newGenerator(s Schema, src rand.Source, opts []Option) *generator {
+	validator := NewValidator(s, opts...)
+	return &generator{
+		rnd:  rand.New(src),
+		root: scope{schema: s, base: schemaBase("", s), opts: validator.options(nil)},
+	}
+}
+
+// resolve returns the scope of the schema the $refs of sc point to.
+func (g *generator) resolve(sc scope, pointer string) (scope, error) {
+	for i := 0; ; i++ {
+		ref, ok := sc.schema.Ref()
+		if !ok {
+			return sc, nil
+		}
+		if i >= sc.opts.maxRefDepth {
+			return sc, fmt.Errorf("Generate Error: more than %d $refs are followed in a row at #%s, it may be circular", sc.opts.maxRefDepth, pointer)
+		}
+		res, err := sc.opts.refs.resolve(sc.base, ref)
+		if err != nil {
+			return sc, fmt.Errorf("Generate Error: $ref %s at #%s: %s", ref, pointer, err)
+		}
+		sc.schema, sc.base = res.schema, res.base
+	}
+}
+
+// flatten returns the scope of a schema without $ref, allOf, anyOf and oneOf
+// accepting a part of the values sc accepts: the subschemas of allOf and one
+// subschema of anyOf or oneOf are merged into it.
+func (g *generator) flatten(sc scope, pointer string) (scope, error) {
+	sc, err := g.resolve(sc, pointer)
+	if err != nil {
+		return sc, err
+	}
+
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		branches, ok := sc.schema[keyword].([]interface{})
+		if !ok {
+			continue
+		}
+		if keyword != "allOf" && len(branches) > 0 {
+			i := g.rnd.Intn(len(branches))
+			branches = branches[i : i+1]
+		}
+
+		merged := make(Schema, len(sc.schema))
+		for k, v := range sc.schema {
+			if k != keyword {
+				merged[k] = v
+			}
+		}
+		for i, branch := range branches {
+			one, ok := branch.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			sub, err := g.flatten(sc.sub(Schema(one)), fmt.Sprintf("%s/%s/%d", pointer, keyword, i))
+			if err != nil {
+				return sc, err
+			}
+			merged = mergeSchemas(merged, sub.schema)
+		}
+		sc.schema = merged
+	}
+	return sc, nil
+}
+
+// mergeSchemas returns a schema accepting the values a and b both accept, as
+// far as it can tell. The keywords it can not merge are kept from a.
+func mergeSchemas(a Schema, b Schema) Schema {
+	merged := make(Schema, len(a)+len(b))
+	for k, v := range a {
+		merged[k] = v
+	}
+
+	for k, v := range b {
+		current, exist := merged[k]
+		if !exist {
+			merged[k] = v
+			continue
+		}
+
+		currentProps, currentIsMap := current.(map[string]interface{})
+		props, isMap := v.(map[string]interface{})
+		currentValues, currentIsArray := current.([]interface{})
+		values, isArray := v.([]interface{})
+
+		switch {
+		case k == "properties" && currentIsMap && isMap:
+			merged[k] = mergeProperties(currentProps, props)
+		case k == "required" && currentIsArray && isArray:
+			required := append([]interface{}{}, currentValues...)
+			for _, prop := range values {
+				if !containsValue(required, prop) {
+					required = append(required, prop)
+				}
+			}
+			merged[k] = required
+		case k == "type":
+			both := func(t JsonType) bool {
+				return accepts(Schema{"type": current}, t) && accepts(Schema{"type": v}, t)
+			}
+			types := []interface{}{}
+			for _, t := range allTypes {
+				// number covers the integers
+				if both(t) && (t != JsonInteger || !both(JsonNumber)) {
+					types = append(types, string(t))
+				}
+			}
+			merged[k] = types
+		case k == "enum" && currentIsArray && isArray:
+			merged[k] = intersectValues(currentValues, values)
+		case containsString(upperBounds, k) && isNumber(v) && isNumber(current) && toFloat(v) < toFloat(current):
+			merged[k] = v
+		case containsString(lowerBounds, k) && isNumber(v) && isNumber(current) && toFloat(v) > toFloat(current):
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// mergeProperties returns the properties of a and b, the ones of both being
+// merged with allOf.
+func mergeProperties(a map[string]interface{}, b map[string]interface{}) map[string]interface{} {
+	props := make(map[string]interface{}, len(a)+len(b))
+	for prop, one := range a {
+		props[prop] = one
+	}
+	for prop, one := range b {
+		if other, ok := props[prop]; ok {
+			props[prop] = map[string]interface{}{"allOf": []interface{}{other, one}}
+		} else {
+			props[prop] = one
+		}
+	}
+	return props
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, one := range values {
+		if reflect.DeepEqual(one, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func intersectValues(a []interface{}, b []interface{}) []interface{} {
+	var both []interface{}
+	for _, v := range a {
+		if containsValue(b, v) {
+			both = append(both, v)
+		}
+	}
+	return both
+}
+
+// generate returns a value valid against the schema of sc at pointer, nested
+// depth levels deep in the generated value.
+func (g *generator) generate(sc scope, pointer string, depth int) (interface{}, error) {
+	if depth > maxGenerateDepth {
+		return nil, fmt.Errorf("Generate Error: values nest deeper than %d levels at #%s, the schema may recurse without end", maxGenerateDepth, pointer)
+	}
+	sc, err := g.resolve(sc, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < generateAttempts; attempt++ {
+		flat, err := g.flatten(sc, pointer)
+		if err != nil {
+			return nil, err
+		}
+		v, err := g.generateFlat(flat, pointer, depth)
+		if err != nil {
+			return nil, err
+		}
+		if sc.accepts(v) {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("Generate Error: no valid value found at #%s in %d attempts", pointer, generateAttempts)
+}
+
+// generateFlat returns a value for the flattened schema of sc, which may not be
+// valid if some of its keywords are not followed.
+func (g *generator) generateFlat(sc scope, pointer string, depth int) (interface{}, error) {
+	s := sc.schema
+	if v, ok := s["const"]; ok {
+		return copyValue(v), nil
+	}
+	if values, ok := s["enum"].([]interface{}); ok {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("Generate Error: no value is allowed at #%s", pointer)
+		}
+		return copyValue(values[g.rnd.Intn(len(values))]), nil
+	}
+
+	switch g.chooseType(s) {
+	case JsonNull:
+		return nil, nil
+	case JsonBoolean:
+		return g.rnd.Intn(2) == 0, nil
+	case JsonInteger:
+		return g.generateNumber(s, true), nil
+	case JsonNumber:
+		return g.generateNumber(s, false), nil
+	case JsonString:
+		return g.generateString(s, pointer)
+	case JsonArray:
+		return g.generateArray(sc, pointer, depth)
+	case JsonObject:
+		return g.generateObject(sc, pointer, depth)
+	default:
+		return nil, fmt.Errorf("Generate Error: no type is allowed at #%s", pointer)
+	}
+}
+
+// typeHints are keywords telling the type of the values of a schema without
+// type.
+var typeHints = []struct {
+	t        JsonType
+	keywords []string
+}{
+	{JsonObject, typeKeywords[JsonObject]},
+	{JsonArray, typeKeywords[JsonArray]},
+	{JsonString, typeKeywords[JsonString]},
+	{JsonNumber, typeKeywords[JsonNumber]},
+}
+
+// chooseType returns the type of the value to generate for s.
+func (g *generator) chooseType(s Schema) JsonType {
+	if _, ok := s["type"]; !ok {
+		for _, hint := range typeHints {
+			for _, keyword := range hint.keywords {
+				if _, ok := s[keyword]; ok {
+					return hint.t
+				}
+			}
+		}
+	}
+
+	var types []JsonType
+	for _, t := range allTypes {
+		if accepts(s, t) {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return ""
+	}
+	return types[g.rnd.Intn(len(types))]
+}
+
+// generateNumber returns a number within the bounds of s, a multiple of its
+// multipleOf, and an integer if integer is set.
+func (g *generator) generateNumber(s Schema, integer bool) json.Number {
+	lower, upper := numberBound(s, false), numberBound(s, true)
+	lo, hi := -float64(defaultSpan), float64(defaultSpan)
+	switch {
+	case lower.exist && upper.exist:
+		lo, hi = lower.value, upper.value
+	case lower.exist:
+		lo, hi = lower.value, lower.value+defaultSpan
+	case upper.exist:
+		lo, hi = upper.value-defaultSpan, upper.value
+	}
+
+	step := 0.0
+	if v, ok := s["multipleOf"]; ok && isNumber(v) && toFloat(v) > 0 {
+		step = toFloat(v)
+	}
+	if integer && step == 0 {
+		step = 1
+	}
+
+	if step > 0 {
+		// the multiples strictly within the exclusive bounds
+		first, last := math.Ceil(lo/step), math.Floor(hi/step)
+		if lower.exclusive && first*step <= lo {
+			first++
+		}
+		if upper.exclusive && last*step >= hi {
+			last--
+		}
+		k := first
+		if last > first {
+			k += float64(g.rnd.Int63n(int64(math.Min(last-first, math.MaxInt32)) + 1))
+		}
+		return formatNumber(k * step)
+	}
+
+	v := lo + g.rnd.Float64()*(hi-lo)
+	if (lower.exclusive && v <= lo) || (upper.exclusive && v >= hi) {
+		v = (lo + hi) / 2
+	}
+	return formatNumber(v)
+}
+
+func formatNumber(f float64) json.Number {
+	return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// generateString returns a string matching the pattern of s, or of the format
+// of s, or of random letters, of a length within the bounds of s.
+func (g *generator) generateString(s Schema, pointer string) (interface{}, error) {
+	if pattern, ok := s.Pattern(); ok {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("Generate Error: invalid pattern at #%s: %s", pointer, err)
+		}
+		var b strings.Builder
+		if err := g.generatePattern(&b, re.Simplify()); err != nil {
+			return nil, fmt.Errorf("Generate Error: pattern %s at #%s: %s", pattern, pointer, err)
+		}
+		return b.String(), nil
+	}
+
+	if format, ok := s["format"].(string); ok {
+		if v, ok := g.generateFormat(format); ok {
+			return v, nil
+		}
+	}
+
+	min, _ := s.MinLength()
+	max, exist := s.MaxLength()
+	if !exist || max > min+defaultLength {
+		max = min + defaultLength
+	}
+	n := min + g.rnd.Intn(max-min+1)
+
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[g.rnd.Intn(len(letters))]
+	}
+	return string(b), nil
+}
+
+// generateFormat returns a string of format, if it is a known one.
+func (g *generator) generateFormat(format string) (string, bool) {
+	switch format {
+	case "date-time":
+		return fmt.Sprintf("20%02d-%02d-%02dT%02d:%02d:%02dZ", g.rnd.Intn(100), 1+g.rnd.Intn(12), 1+g.rnd.Intn(28), g.rnd.Intn(24), g.rnd.Intn(60), g.rnd.Intn(60)), true
+	case "date":
+		return fmt.Sprintf("20%02d-%02d-%02d", g.rnd.Intn(100), 1+g.rnd.Intn(12), 1+g.rnd.Intn(28)), true
+	case "time":
+		return fmt.Sprintf("%02d:%02d:%02dZ", g.rnd.Intn(24), g.rnd.Intn(60), g.rnd.Intn(60)), true
+	case "email":
+		return fmt.Sprintf("user%d@example.com", g.rnd.Intn(10000)), true
+	case "hostname":
+		return fmt.Sprintf("host%d.example.com", g.rnd.Intn(10000)), true
+	case "ipv4":
+		return fmt.Sprintf("%d.%d.%d.%d", g.rnd.Intn(256), g.rnd.Intn(256), g.rnd.Intn(256), g.rnd.Intn(256)), true
+	case "uri":
+		return fmt.Sprintf("https://example.com/%d", g.rnd.Intn(10000)), true
+	case "uuid":
+		return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x", g.rnd.Uint32(), g.rnd.Intn(1<<16), g.rnd.Intn(1<<12), 0x8000|g.rnd.Intn(1<<14), g.rnd.Int63n(1<<48)), true
+	}
+	return "", false
+}
+
+// generatePattern writes a string matching re to b.
+func (g *generator) generatePattern(b *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && g.rnd.Intn(2) == 0 {
+				r = []rune(strings.ToUpper(string(r)))[0]
+			}
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(g.classRune(re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		b.WriteRune(rune('a' + g.rnd.Intn(26)))
+	case syntax.OpCapture:
+		return g.generatePattern(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.generatePattern(b, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		return g.generatePattern(b, re.Sub[g.rnd.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 || max > min+maxPatternRepeat {
+			max = min + maxPatternRepeat
+		}
+		for i, n := 0, min+g.rnd.Intn(max-min+1); i < n; i++ {
+			if err := g.generatePattern(b, re.Sub[0]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%s is not supported", re)
+	}
+	return nil
+}
+
+// classRune returns a rune of the character class of ranges, a printable ASCII
+// one if there is one.
+func (g *generator) classRune(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r <= '~'; r++ {
+			if r >= ' ' {
+				printable = append(printable, r)
+			}
+		}
+	}
+	if len(printable) > 0 {
+		return printable[g.rnd.Intn(len(printable))]
+	}
+
+	i := 2 * g.rnd.Intn(len(ranges)/2)
+	return ranges[i] + rune(g.rnd.Int63n(int64(ranges[i+1]-ranges[i])+1))
+}
+
+func (g *generator) generateArray(sc scope, pointer string, depth int) (interface{}, error) {
+	s := sc.schema
+	min, _ := s.MinItems()
+	max, exist := s.MaxItems()
+	if limit, ok := maxLimit(s, "maxItems"); ok && (!exist || int(limit) < max) {
+		max, exist = int(limit), true
+	}
+	if !exist || max > min+defaultItems {
+		max = min + defaultItems
+	}
+	n := min
+	if depth < optionalDepth && max > min {
+		n += g.rnd.Intn(max - min + 1)
+	}
+
+	arr := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		item, _, _ := itemSchema(s, i)
+		if item == nil {
+			item = Schema{}
+		}
+		v, err := g.generate(sc.sub(item), fmt.Sprintf("%s/items/%d", pointer, i), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+
+	if contains, ok := s["contains"].(map[string]interface{}); ok && n > 0 {
+		v, err := g.generate(sc.sub(Schema(contains)), pointer+"/contains", depth+1)
+		if err != nil {
+			return nil, err
+		}
+		arr[g.rnd.Intn(n)] = v
+	}
+	return arr, nil
+}
+
+func (g *generator) generateObject(sc scope, pointer string, depth int) (interface{}, error) {
+	s := sc.schema
+	props, _ := s.Properties()
+	required, _ := s.Required()
+	max, exist := s.MaxProperties()
+
+	// the required properties, then some of the others
+	names := append([]string{}, required...)
+	if depth < optionalDepth {
+		for _, prop := range sortedKeys(propertyKeys(props)) {
+			if !containsString(names, prop) && g.rnd.Intn(2) == 0 && (!exist || len(names) < max) {
+				names = append(names, prop)
+			}
+		}
+	}
+
+	// and new ones up to minProperties, if allowed
+	min, _ := s.MinProperties()
+	additionSchema, allowAddition, additionExist := s.AdditionalProperties()
+	for i := 0; len(names) < min; i++ {
+		prop := fmt.Sprintf("property%d", i)
+		if _, declared := props[prop]; declared || containsString(names, prop) {
+			continue
+		}
+		if additionExist && additionSchema == nil && !allowAddition {
+			break
+		}
+		names = append(names, prop)
+	}
+
+	obj := make(map[string]interface{}, len(names))
+	for _, prop := range names {
+		propSchema, ok := props[prop]
+		if !ok {
+			if propSchema = additionSchema; propSchema == nil {
+				propSchema = Schema{}
+			}
+		}
+		v, err := g.generate(sc.sub(propSchema), pointer+"/properties/"+escapePointer(prop), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[prop] = v
+	}
+	return obj, nil
+}
+
+// mutation replaces the value at path in a value, or removes it.
+type mutation struct {
+	path   []interface{}
+	value  interface{}
+	remove bool
+}
+
+// apply returns a copy of v with the mutation.
+func (m mutation) apply(v interface{}) interface{} {
+	if len(m.path) == 0 {
+		return copyValue(m.value)
+	}
+
+	v = copyValue(v)
+	parent := v
+	for _, step := range m.path[:len(m.path)-1] {
+		switch step := step.(type) {
+		case string:
+			parent = parent.(map[string]interface{})[step]
+		case int:
+			parent = parent.([]interface{})[step]
+		}
+	}
+
+	switch last := m.path[len(m.path)-1].(type) {
+	case string:
+		if m.remove {
+			delete(parent.(map[string]interface{}), last)
+		} else {
+			parent.(map[string]interface{})[last] = copyValue(m.value)
+		}
+	case int:
+		parent.([]interface{})[last] = copyValue(m.value)
+	}
+	return v
+}
+
+// typeSamples are values of each type.
+var typeSamples = []interface{}{
+	nil, true, json.Number("1"), json.Number("1.5"), "a", []interface{}{}, map[string]interface{}{},
+}
+
+// mutations returns the changes of v, valid against the schema of sc, that may
+// break one keyword of the schema, flattened.
+func (g *generator) mutations(sc scope, v interface{}, path []interface{}) ([]mutation, error) {
+	sc, err := g.flatten(sc, "")
+	if err != nil {
+		return nil, err
+	}
+	s := sc.schema
+	replace := func(value interface{}) mutation {
+		return mutation{path: path, value: value}
+	}
+
+	var mutations []mutation
+	if _, ok := s["type"]; ok {
+		for _, sample := range typeSamples {
+			if t, _ := getJsonType(sample); !accepts(s, t) {
+				mutations = append(mutations, replace(sample))
+			}
+		}
+	}
+	if values, _, ok := enumValues(s); ok {
+		for _, sample := range append(typeSamples, "invalid") {
+			if !containsValue(values, sample) {
+				mutations = append(mutations, replace(sample))
+			}
+		}
+	}
+
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		if bd := numberBound(s, true); bd.exist {
+			mutations = append(mutations, replace(formatNumber(bd.value+1)), replace(formatNumber(bd.value)))
+		}
+		if bd := numberBound(s, false); bd.exist {
+			mutations = append(mutations, replace(formatNumber(bd.value-1)), replace(formatNumber(bd.value)))
+		}
+		if m, ok := s["multipleOf"]; ok && isNumber(m) {
+			mutations = append(mutations, replace(formatNumber(f+toFloat(m)/2)))
+		}
+
+	case string:
+		if max, ok := s.MaxLength(); ok {
+			mutations = append(mutations, replace(strings.Repeat("a", max+1)))
+		}
+		if min, ok := s.MinLength(); ok && min > 0 {
+			mutations = append(mutations, replace(strings.Repeat("a", min-1)))
+		}
+		if _, ok := s.Pattern(); ok {
+			mutations = append(mutations, replace(""), replace(v+"~"), replace("~"+v))
+		}
+
+	case []interface{}:
+		if max, ok := s.MaxItems(); ok {
+			arr := append([]interface{}{}, v...)
+			for len(arr) <= max {
+				if len(v) > 0 {
+					arr = append(arr, v[0])
+				} else {
+					arr = append(arr, nil)
+				}
+			}
+			mutations = append(mutations, replace(arr))
+		}
+		if min, ok := s.MinItems(); ok && min > 0 && len(v) >= min {
+			mutations = append(mutations, replace(v[:min-1]))
+		}
+		if s.UniqueItems() && len(v) > 0 {
+			mutations = append(mutations, replace(append(append([]interface{}{}, v...), v[0])))
+		}
+		for i, item := range v {
+			if one, _, _ := itemSchema(s, i); one != nil {
+				sub, err := g.mutations(sc.sub(one), item, append(append([]interface{}{}, path...), i))
+				if err != nil {
+					return nil, err
+				}
+				mutations = append(mutations, sub...)
+			}
+		}
+
+	case map[string]interface{}:
+		required, _ := s.Required()
+		for _, prop := range required {
+			if _, ok := v[prop]; ok {
+				mutations = append(mutations, mutation{path: append(append([]interface{}{}, path...), prop), remove: true})
+			}
+		}
+		_, additionExist := s["additionalProperties"]
+		if _, maxExist := s["maxProperties"]; additionExist || maxExist {
+			mutations = append(mutations, mutation{path: append(append([]interface{}{}, path...), "undeclaredProperty"), value: true})
+		}
+		if min, ok := s.MinProperties(); ok && min > 0 && len(v) > 0 {
+			mutations = append(mutations, mutation{path: append(append([]interface{}{}, path...), sortedKeys(Schema(v))[0]), remove: true})
+		}
+		props, _ := s.Properties()
+		for _, prop := range sortedKeys(Schema(v)) {
+			if one, ok := props[prop]; ok {
+				sub, err := g.mutations(sc.sub(one), v[prop], append(append([]interface{}{}, path...), prop))
+				if err != nil {
+					return nil, err
+				}
+				mutations = append(mutations, sub...)
+			}
+		}
+	}
+	return mutations, nil
+}
@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	schemas := []string{
+		`{}`,
+		`{"type": "integer", "minimum": 3, "exclusiveMaximum": 9, "multipleOf": 3}`,
+		`{"type": "number", "exclusiveMinimum": 0, "maximum": 1}`,
+		`{"type": "string", "minLength": 2, "maxLength": 4}`,
+		`{"type": "string", "pattern": "^[A-Z]{2}-\\d{3,5}(x|yz)?$"}`,
+		`{"type": "string", "format": "uuid"}`,
+		`{"enum": ["a", 1, null]}`,
+		`{"const": {"a": [1]}}`,
+		`{"type": "array", "items": {"type": "boolean"}, "minItems": 1, "maxItems": 2}`,
+		`{"type": "array", "items": [{"type": "string"}, {"type": "null"}], "additionalItems": false}`,
+		`{"type": "array", "items": {"type": "integer", "minimum": 0, "maximum": 5}, "minItems": 3, "uniqueItems": true}`,
+		`{
+			"type": "object",
+			"properties": {"id": {"type": "integer", "minimum": 1}, "name": {"type": "string", "maxLength": 3}, "tags": {"type": "array", "items": {"$ref": "#/definitions/tag"}}},
+			"required": ["id", "name"],
+			"additionalProperties": false,
+			"definitions": {"tag": {"type": "string", "minLength": 1}}
+		}`,
+		`{"type": "object", "minProperties": 2, "additionalProperties": {"type": "integer"}}`,
+		`{"oneOf": [{"type": "string", "maxLength": 2}, {"type": "integer", "maximum": 0}]}`,
+		`{"allOf": [{"type": ["string", "integer"]}, {"type": ["integer", "null"], "minimum": 10}]}`,
+		`{"type": "object", "properties": {"value": {"type": "integer"}, "next": {"$ref": "#"}}, "required": ["value"]}`,
+		`{"type": "integer", "not": {"enum": [1, 2]}, "minimum": 1, "maximum": 3}`,
+	}
+
+	for _, str := range schemas {
+		s, err := deserializeSchema(str)
+		assert.NoError(t, err)
+		validator := NewValidator(s)
+
+		for seed := int64(0); seed < 20; seed++ {
+			v, err := Generate(s, rand.NewSource(seed))
+			if assert.NoError(t, err, str) {
+				assert.True(t, validator.Validate(v).Valid(), "%s: %v", str, v)
+			}
+
+			// the same source gives the same value
+			again, err := Generate(s, rand.NewSource(seed))
+			assert.NoError(t, err, str)
+			assert.Equal(t, v, again, str)
+
+			// const is not validated
+			if str == `{}` || str == `{"const": {"a": [1]}}` {
+				continue
+			}
+			invalid, err := GenerateInvalid(s, rand.NewSource(seed))
+			if assert.NoError(t, err, str) {
+				assert.False(t, validator.Validate(invalid).Valid(), "%s: %v", str, invalid)
+			}
+		}
+	}
+}
+
+func TestGenerateError(t *testing.T) {
+	tests := []struct {
+		schema string
+		err    string
+	}{
+		{
+			schema: `{"type": "string", "pattern": "\\bword"}`,
+			err:    `Generate Error: pattern \bword at #: \b is not supported`,
+		},
+		{
+			schema: `{"properties": {"a": {"$ref": "#/definitions/missing"}}, "required": ["a"]}`,
+			err:    "Generate Error: $ref #/definitions/missing at #/properties/a: Resolve Error: no definitions in #/definitions/missing",
+		},
+		{
+			schema: `{"type": "integer", "minimum": 1, "maximum": 0}`,
+			err:    "Generate Error: no valid value found at # in 20 attempts",
+		},
+		{
+			schema: `{"type": "object", "properties": {"next": {"$ref": "#"}}, "required": ["next"]}`,
+			err:    "Generate Error: values nest deeper than 32 levels at #/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next, the schema may recurse without end",
+		},
+	}
+
+	for _, test := range tests {
+		s, err := deserializeSchema(test.schema)
+		assert.NoError(t, err)
+		_, err = Generate(s, rand.NewSource(1))
+		assert.EqualError(t, err, test.err, test.schema)
+	}
+
+	_, err := GenerateInvalid(Schema{"description": "anything"}, rand.NewSource(1))
+	assert.EqualError(t, err, "Generate Error: no invalid value found, the schema may accept any value")
+}
@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// maxReplacements is the number of times a value can be replaced in a row, so
+// a resolver replacing every value with an invalid one does not loop forever.
+const maxReplacements = 100
+
+// Decision tells an interactive validation how to go on after an error.
+type Decision int
+
+const (
+	// Continue keeps the error and goes on with the validation.
+	Continue Decision = iota
+	// Abort keeps the error and stops the whole validation.
+	Abort
+	// Skip keeps the error and stops validating the value that raised it.
+	Skip
+	// Replace drops the errors of the value that raised the error and validates
+	// the value returned with the decision in its place.
+	Replace
+)
+
+// Resolver is asked for a decision about every error of an interactive
+// validation. v is the value validated by the subschema that raised e, which
+// for some array and object keywords is the container of the invalid item.
+//
+// Values are replaced in place in their array or object, and the replaced root
+// value is returned in Result.Value. A replacement is converted to the values of
+// encoding/json, with json.Number numbers, and one that is not a JSON value is
+// reported as an InvalidReplacementError. After 100 replacements in a row of
+// the same value, a MaxReplacementsError is reported instead.
+type Resolver interface {
+	Resolve(e SchemaError, v interface{}) (Decision, interface{})
+}
+
+type interaction struct {
+	resolver Resolver
+	aborted  bool
+}
+
+// resolve asks the resolver about e, and reports whether e must be collected.
+func (b *baseConstraint) resolve(e SchemaError) bool {
+	if b.opts == nil || b.opts.interaction == nil || b.opts.probing {
+		return true
+	}
+
+	decision, value := b.opts.interaction.resolver.Resolve(e, b.value)
+	switch decision {
+	case Abort:
+		b.opts.interaction.aborted = true
+	case Skip:
+		b.skipped = true
+	case Replace:
+		b.replaced, b.replacement = true, value
+		return false
+	}
+	return true
+}
+
+func (b *baseConstraint) aborted() bool {
+	return b.opts != nil && b.opts.interaction != nil && b.opts.interaction.aborted
+}
+
+// jsonValue converts v to the values of encoding/json, with json.Number numbers,
+// as the validated instances.
+func jsonValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
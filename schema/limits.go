@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+// DefaultMaxRefDepth is the number of $refs followed in a row from which a $ref
+// is considered circular, when WithMaxRefDepth is not given.
+const DefaultMaxRefDepth = 100
+
+// evaluate counts the evaluation of b's schema, and reports whether it is
+// allowed by the maximum evaluations option.
+func (b *baseConstraint) evaluate() bool {
+	if b.opts == nil || b.opts.maxEvaluations <= 0 || b.opts.evaluations == nil {
+		return true
+	}
+
+	if atomic.AddInt64(b.opts.evaluations, 1) > int64(b.opts.maxEvaluations) {
+		atomic.StoreInt32(b.opts.exhausted, 1)
+		return false
+	}
+	return true
+}
+
+// exhausted reports whether the validation evaluated the maximum number of
+// subschemas.
+func (b *baseConstraint) exhausted() bool {
+	if b.opts == nil || b.opts.exhausted == nil {
+		return false
+	}
+	return atomic.LoadInt32(b.opts.exhausted) == 1
+}
+
+// addLimitError adds the error telling that the validation was stopped by the
+// maximum evaluations option, if it was.
+func (b *baseConstraint) addLimitError(path string) {
+	if !b.exhausted() {
+		return
+	}
+	b.errors = append(b.errors, newError(MaxEvaluationsError, path).withParam("limit", b.opts.maxEvaluations))
+}
+
+// tooDeep returns the path of the first array or object of v nested deeper than
+// max, v being at depth.
+func tooDeep(v interface{}, path string, depth int, max int) (string, bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		if depth >= max {
+			return path, true
+		}
+		for i, item := range v {
+			if p, ok := tooDeep(item, fmt.Sprintf("%s[%d]", path, i), depth+1, max); ok {
+				return p, true
+			}
+		}
+	case map[string]interface{}:
+		if depth >= max {
+			return path, true
+		}
+		// sorted, so that the same path is reported every time
+		props := make([]string, 0, len(v))
+		for prop := range v {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+
+		for _, prop := range props {
+			if p, ok := tooDeep(v[prop], fmt.Sprintf("%s.%s", path, prop), depth+1, max); ok {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxDepth(t *testing.T) {
+	s := Schema{"type": "object"}
+	v, err := deserializeValue(`{"a": [1, {"b": [2]}], "c": {}}`)
+	assert.NoError(t, err)
+
+	res := NewValidator(s, WithMaxDepth(3)).Validate(v)
+	assert.Equal(t, []SchemaError{newError(MaxDepthError, "$.a[1].b").withParam("limit", 3)}, res.Errors)
+	assert.Equal(t, []string{"$.a[1].b: is nested deeper than the limit of 3"}, res.Messages())
+
+	res = NewValidator(s, WithMaxDepth(4)).Validate(v)
+	assert.True(t, res.Valid())
+
+	res, err = NewValidator(s, WithMaxDepth(3)).ValidateReader(strings.NewReader(`{"a": [1, {"b": [2]}], "c": {}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaError{newError(MaxDepthError, "$").withParam("limit", 3)}, res.Errors)
+
+	res, err = NewValidator(s, WithMaxDepth(4)).ValidateReader(strings.NewReader(`{"a": [1, {"b": [2]}], "c": {}}`))
+	assert.NoError(t, err)
+	assert.True(t, res.Valid())
+}
+
+func TestMaxEvaluations(t *testing.T) {
+	s := Schema{
+		"items": map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "integer"},
+			},
+		},
+	}
+	v, err := deserializeValue(`[1, 2, 3, 4]`)
+	assert.NoError(t, err)
+
+	// the array, and per item its schema and the two subschemas of anyOf
+	res := NewValidator(s, WithMaxEvaluations(13)).Validate(v)
+	assert.True(t, res.Valid())
+
+	res = NewValidator(s, WithMaxEvaluations(12)).Validate(v)
+	assert.Equal(t, []SchemaError{newError(MaxEvaluationsError, "$").withParam("limit", 12)}, res.Errors)
+
+	res, err = NewValidator(s, WithMaxEvaluations(5)).ValidateReader(strings.NewReader(`[1, 2, 3, 4]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaError{newError(MaxEvaluationsError, "$").withParam("limit", 5)}, res.Errors)
+}
+
+func TestMaxPatternLength(t *testing.T) {
+	s := Schema{"pattern": "^a+$"}
+
+	res := NewValidator(s, WithMaxPatternLength(3)).Validate("aa")
+	assert.Equal(t, []string{"$: can not be validated, the pattern ^a+$ is invalid: it is longer than 3 bytes"}, res.Messages())
+
+	res = NewValidator(s, WithMaxPatternLength(4)).Validate("aa")
+	assert.True(t, res.Valid())
+}
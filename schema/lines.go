@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+type lineJob struct {
+	line    int
+	data    []byte
+	results chan *Result
+}
+
+// ValidateLines validates every line of r as a JSON document (JSON Lines, or
+// NDJSON) and calls fn with its 1-based line number and result, in the order of
+// the lines. Blank lines are skipped. A line that is not valid JSON is reported
+// with an InvalidJSONError instead of stopping the validation.
+//
+// The returned error is only set if r could not be read.
+func (validator *Validator) ValidateLines(r io.Reader, fn func(line int, res *Result), opts ...Option) error {
+	o := validator.options(opts)
+	// as for WithParallel, the event handlers are not called concurrently
+	if o.lineWorkers <= 1 || o.emitter != nil {
+		return readLines(r, func(line int, data []byte) {
+			fn(line, validator.validateLine(data, opts))
+		})
+	}
+
+	jobs := make(chan *lineJob)
+	// pending holds the jobs in the order of the lines, so the results are
+	// reported in order whichever worker finishes first
+	pending := make(chan *lineJob, o.lineWorkers)
+	done := make(chan struct{})
+
+	for i := 0; i < o.lineWorkers; i++ {
+		go func() {
+			for job := range jobs {
+				job.results <- validator.validateLine(job.data, opts)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(done)
+		for job := range pending {
+			fn(job.line, <-job.results)
+		}
+	}()
+
+	err := readLines(r, func(line int, data []byte) {
+		job := &lineJob{line: line, data: data, results: make(chan *Result, 1)}
+		pending <- job
+		jobs <- job
+	})
+
+	close(jobs)
+	close(pending)
+	<-done
+
+	return err
+}
+
+func (validator *Validator) validateLine(data []byte, opts []Option) *Result {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	err := dec.Decode(&v)
+	if err == nil {
+		// anything but the end of the line after the document, even a closing
+		// delimiter that More does not see, is an error
+		if _, end := dec.Token(); end != io.EOF {
+			err = errors.New("unexpected data after the document")
+		}
+	}
+	if err != nil {
+		return &Result{
+			Errors:    []SchemaError{newError(InvalidJSONError, rootPath).withParam("error", err.Error())},
+			formatter: validator.options(opts).formatter,
+		}
+	}
+
+	return validator.Validate(v, opts...)
+}
+
+// readLines calls fn with every non blank line of r.
+func readLines(r io.Reader, fn func(line int, data []byte)) error {
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			fn(line, data)
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLines(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"id": {"type": "integer"}
+		}
+	}
+	`)
+	assert.NoError(t, err)
+
+	input := strings.Join([]string{
+		`{"id": 1}`,
+		`{"id": "a"}`,
+		``,
+		`{"id": `,
+		`{"id": 2} {"id": 3}`,
+		`{}`,
+		`{"id":1}]`,
+		`{"a":1}}`,
+	}, "\n")
+
+	expected := []string{
+		"1: ",
+		"2: $.id: must be of type integer",
+		"4: $: is not valid JSON: unexpected EOF",
+		"5: $: is not valid JSON: unexpected data after the document",
+		"6: $: is missing the required property id",
+		"7: $: is not valid JSON: unexpected data after the document",
+		"8: $: is not valid JSON: unexpected data after the document",
+	}
+
+	v := NewValidator(s)
+	for _, opts := range [][]Option{nil, {WithLineWorkers(3)}} {
+		var actual []string
+		err := v.ValidateLines(strings.NewReader(input), func(line int, res *Result) {
+			actual = append(actual, fmt.Sprintf("%d: %s", line, strings.Join(res.Messages(), ", ")))
+		}, opts...)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestValidateLinesOrder(t *testing.T) {
+	var lines []string
+	for i := 0; i < 1000; i++ {
+		if i%3 == 0 {
+			lines = append(lines, `"str"`)
+		} else {
+			lines = append(lines, fmt.Sprintf("%d", i))
+		}
+	}
+
+	var invalid []int
+	v := NewValidator(Schema{"type": "integer"})
+	err := v.ValidateLines(strings.NewReader(strings.Join(lines, "\n")), func(line int, res *Result) {
+		if !res.Valid() {
+			invalid = append(invalid, line)
+		}
+	}, WithLineWorkers(8))
+	assert.NoError(t, err)
+
+	assert.Len(t, invalid, 334)
+	for i, line := range invalid {
+		assert.Equal(t, i*3+1, line)
+	}
+}
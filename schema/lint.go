@@ -0,0 +1,341 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// LintRule names a check of Lint.
+type LintRule string
+
+const (
+	// LintTypeKeyword flags the keywords that do not apply to any declared type,
+	// such as maxLength on an integer.
+	LintTypeKeyword = LintRule("type-keyword")
+	// LintRequiredUndeclared flags the required properties missing in properties.
+	LintRequiredUndeclared = LintRule("required-undeclared")
+	// LintUnreachableOneOf flags the oneOf branches no value can match alone.
+	LintUnreachableOneOf = LintRule("unreachable-oneof")
+	// LintMinMax flags the lower bounds greater than their upper bound.
+	LintMinMax = LintRule("min-max")
+	// LintUnknownKeyword flags the keywords that are not JSON Schema keywords.
+	LintUnknownKeyword = LintRule("unknown-keyword")
+	// LintUnresolvedRef flags the $refs that can not be resolved.
+	LintUnresolvedRef = LintRule("unresolved-ref")
+	// LintAdditionalProperties flags the object schemas without
+	// additionalProperties.
+	LintAdditionalProperties = LintRule("additional-properties")
+)
+
+// LintRules are all the rules, in the order they are checked.
+var LintRules = []LintRule{
+	LintUnknownKeyword,
+	LintTypeKeyword,
+	LintMinMax,
+	LintRequiredUndeclared,
+	LintAdditionalProperties,
+	LintUnreachableOneOf,
+	LintUnresolvedRef,
+}
+
+// LintIssue is a likely mistake found by Lint.
+type LintIssue struct {
+	Rule LintRule
+	// Pointer is the JSON pointer of the subschema in the linted schema.
+	Pointer string
+	Message string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("#%s: %s (%s)", i.Pointer, i.Message, i.Rule)
+}
+
+// typeKeywords are the keywords that only apply to the values of a type, from
+// draft 4 to 2020-12.
+var typeKeywords = map[JsonType][]string{
+	JsonNumber: {"exclusiveMaximum", "exclusiveMinimum", "maximum", "minimum", "multipleOf"},
+	JsonString: {"contentEncoding", "contentMediaType", "contentSchema", "maxLength", "minLength", "pattern"},
+	JsonArray: {
+		"additionalItems", "contains", "items", "maxContains", "maxItems", "minContains",
+		"minItems", "prefixItems", "unevaluatedItems", "uniqueItems",
+	},
+	JsonObject: {
+		"additionalProperties", "dependencies", "dependentRequired", "dependentSchemas",
+		"maxProperties", "minProperties", "patternProperties", "properties", "propertyNames",
+		"required", "unevaluatedProperties",
+	},
+}
+
+// anyTypeKeywords are the keywords that apply to the values of any type, from
+// draft 4 to 2020-12.
+var anyTypeKeywords = []string{
+	"$anchor", "$comment", "$defs", "$dynamicAnchor", "$dynamicRef", "$id",
+	"$recursiveAnchor", "$recursiveRef", "$ref", "$schema", "$vocabulary",
+	"allOf", "anyOf", "const", "default", "definitions", "deprecated",
+	"description", "else", "enum", "errorMessage", "examples", "format", "id", "if",
+	"not", "oneOf", "readOnly", "then", "title", "type", "writeOnly",
+}
+
+// minMaxKeywords are the pairs of lower and upper bounds.
+var minMaxKeywords = [][2]string{
+	{"minimum", "maximum"},
+	{"minLength", "maxLength"},
+	{"minItems", "maxItems"},
+	{"minProperties", "maxProperties"},
+}
+
+// Lint returns the likely mistakes of s, in the order of Walk. Every rule is
+// checked, unless WithLintRules or WithoutLintRules is given. The
+// unresolved-ref rule reports the $refs to a document that is neither given
+// with WithSchema nor allowed to be loaded by WithRemoteRefs or WithFileRefs.
+func Lint(s Schema, opts ...Option) []LintIssue {
+	validator := NewValidator(s, opts...)
+	l := &linter{opts: validator.options(nil)}
+
+	walkScoped(s, "", schemaBase("", s), func(pointer string, base string, sub Schema) error {
+		for _, rule := range LintRules {
+			if l.opts.lintRule(rule) {
+				l.check(rule, pointer, base, sub)
+			}
+		}
+		return nil
+	})
+	return l.issues
+}
+
+type linter struct {
+	opts   *options
+	issues []LintIssue
+}
+
+func (l *linter) report(rule LintRule, pointer string, format string, args ...interface{}) {
+	l.issues = append(l.issues, LintIssue{Rule: rule, Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) check(rule LintRule, pointer string, base string, s Schema) {
+	switch rule {
+	case LintUnknownKeyword:
+		l.checkUnknownKeywords(pointer, s)
+	case LintTypeKeyword:
+		l.checkTypeKeywords(pointer, s)
+	case LintMinMax:
+		for _, pair := range minMaxKeywords {
+			min, minExist := s[pair[0]]
+			max, maxExist := s[pair[1]]
+			if minExist && maxExist && toFloat(min) > toFloat(max) {
+				l.report(rule, pointer, "%s %v is greater than %s %v", pair[0], min, pair[1], max)
+			}
+		}
+	case LintRequiredUndeclared:
+		required, _ := s.Required()
+		props, _ := s.Properties()
+		patterns, _ := s.PatternProperties()
+		for _, prop := range required {
+			if _, ok := props[prop]; !ok && len(patterns) == 0 {
+				l.report(rule, pointer, "required property %s is not declared in properties", prop)
+			}
+		}
+	case LintAdditionalProperties:
+		t, types, _ := s.Type()
+		_, hasProperties := s["properties"]
+		if _, ok := s["additionalProperties"]; !ok && (t == JsonObject || containsType(types, JsonObject) || hasProperties) {
+			l.report(rule, pointer, "object schema without additionalProperties")
+		}
+	case LintUnreachableOneOf:
+		l.checkOneOf(pointer, s)
+	case LintUnresolvedRef:
+		if ref, ok := s.Ref(); ok {
+			if _, err := l.opts.refs.resolve(base, ref); err != nil {
+				l.report(rule, pointer, "$ref %s can not be resolved: %s", ref, err)
+			}
+		}
+	}
+}
+
+func (l *linter) checkUnknownKeywords(pointer string, s Schema) {
+	known := make(map[string]bool)
+	for _, keyword := range anyTypeKeywords {
+		known[keyword] = true
+	}
+	for _, keywords := range typeKeywords {
+		for _, keyword := range keywords {
+			known[keyword] = true
+		}
+	}
+
+	for _, keyword := range sortedKeys(s) {
+		if known[keyword] {
+			continue
+		}
+		if suggestion := closestKeyword(keyword, known); suggestion != "" {
+			l.report(LintUnknownKeyword, pointer, "unknown keyword %s, did you mean %s?", keyword, suggestion)
+		} else {
+			l.report(LintUnknownKeyword, pointer, "unknown keyword %s", keyword)
+		}
+	}
+}
+
+func (l *linter) checkTypeKeywords(pointer string, s Schema) {
+	t, types, exist := s.Type()
+	if !exist {
+		return
+	}
+	if t != "" {
+		types = []JsonType{t}
+	}
+
+	applies := make(map[string]bool)
+	for _, t := range types {
+		// an integer is a number too
+		if t == JsonInteger {
+			t = JsonNumber
+		}
+		for _, keyword := range typeKeywords[t] {
+			applies[keyword] = true
+		}
+	}
+
+	for _, keyword := range sortedKeys(s) {
+		for keywordType, keywords := range typeKeywords {
+			if !applies[keyword] && containsString(keywords, keyword) {
+				l.report(LintTypeKeyword, pointer, "%s only applies to %s values, not %v", keyword, keywordType, types)
+			}
+		}
+	}
+}
+
+func (l *linter) checkOneOf(pointer string, s Schema) {
+	branches, ok := s.OneOf()
+	if !ok {
+		return
+	}
+	t, types, hasType := s.Type()
+	if t != "" {
+		types = []JsonType{t}
+	}
+
+	for i, branch := range branches {
+		branchPointer := fmt.Sprintf("%s/oneOf/%d", pointer, i)
+
+		for j := 0; j < i; j++ {
+			if reflect.DeepEqual(branches[j], branch) {
+				l.report(LintUnreachableOneOf, branchPointer, "branch is the same as branch %d, so neither can match alone", j)
+			}
+		}
+
+		if len(branch) == 0 {
+			l.report(LintUnreachableOneOf, branchPointer, "branch matches any value, so no other branch can match alone")
+		}
+
+		bt, btypes, branchHasType := branch.Type()
+		if bt != "" {
+			btypes = []JsonType{bt}
+		}
+		if hasType && branchHasType && !typesOverlap(types, btypes) {
+			l.report(LintUnreachableOneOf, branchPointer, "branch types %v exclude the types %v of the schema", btypes, types)
+		}
+	}
+}
+
+// lintRule reports whether Lint checks rule.
+func (o *options) lintRule(rule LintRule) bool {
+	if o.lintRules != nil && !o.lintRules[rule] {
+		return false
+	}
+	return !o.lintDisabled[rule]
+}
+
+// closestKeyword returns the known keyword at most 2 edits away from keyword.
+func closestKeyword(keyword string, known map[string]bool) string {
+	best, bestDistance := "", 3
+	for candidate := range known {
+		if d := editDistance(strings.ToLower(keyword), strings.ToLower(candidate)); d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func sortedKeys(s Schema) []string {
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(strs []string, str string) bool {
+	for _, one := range strs {
+		if one == str {
+			return true
+		}
+	}
+	return false
+}
+
+func containsType(types []JsonType, t JsonType) bool {
+	for _, one := range types {
+		if one == t {
+			return true
+		}
+	}
+	return false
+}
+
+// typesOverlap reports whether a value can be of one of a and one of b.
+func typesOverlap(a []JsonType, b []JsonType) bool {
+	for _, t := range a {
+		for _, u := range b {
+			if t == u || (t == JsonNumber && u == JsonInteger) || (t == JsonInteger && u == JsonNumber) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toFloat returns the number v, or 0.
+func toFloat(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case interface{ Float64() (float64, error) }:
+		f, _ := v.Float64()
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"type": "object",
+		"additionalProperties": false,
+		"requried": ["name"],
+		"required": ["name", "email"],
+		"properties": {
+			"name": {"type": "string", "minLength": 5, "maxLength": 3},
+			"age": {"type": "integer", "maxLength": 3, "minimum": 0},
+			"count": {"type": "integer", "format": "int64"},
+			"address": {"type": "object", "properties": {"city": {}}},
+			"contact": {
+				"type": "string",
+				"oneOf": [
+					{"type": "string", "format": "email"},
+					{"type": "integer"},
+					{"type": "string", "format": "email"},
+					{}
+				]
+			},
+			"parent": {"$ref": "#/definitions/missing"},
+			"recent": {
+				"type": "object",
+				"additionalProperties": false,
+				"dependentRequired": {"a": ["b"]},
+				"dependentSchemas": {"a": {}},
+				"unevaluatedProperties": false
+			},
+			"list": {"type": "array", "prefixItems": [{}], "unevaluatedItems": false, "minContains": 1}
+		},
+		"$defs": {}
+	}`)
+	assert.NoError(t, err)
+
+	issues := Lint(s)
+	var strs []string
+	for _, issue := range issues {
+		strs = append(strs, issue.String())
+	}
+	assert.Equal(t, []string{
+		"#: unknown keyword requried, did you mean required? (unknown-keyword)",
+		"#: required property email is not declared in properties (required-undeclared)",
+		"#/properties/address: object schema without additionalProperties (additional-properties)",
+		"#/properties/age: maxLength only applies to string values, not [integer] (type-keyword)",
+		"#/properties/contact/oneOf/1: branch types [integer] exclude the types [string] of the schema (unreachable-oneof)",
+		"#/properties/contact/oneOf/2: branch is the same as branch 0, so neither can match alone (unreachable-oneof)",
+		"#/properties/contact/oneOf/3: branch matches any value, so no other branch can match alone (unreachable-oneof)",
+		"#/properties/name: minLength 5 is greater than maxLength 3 (min-max)",
+		"#/properties/parent: $ref #/definitions/missing can not be resolved: Resolve Error: no definitions in #/definitions/missing (unresolved-ref)",
+	}, strs)
+
+	issues = Lint(s, WithLintRules(LintMinMax, LintTypeKeyword), WithoutLintRules(LintTypeKeyword))
+	assert.Equal(t, []LintIssue{
+		{Rule: LintMinMax, Pointer: "/properties/name", Message: "minLength 5 is greater than maxLength 3"},
+	}, issues)
+}
@@ -0,0 +1,53 @@
+package schema
+
+import "sync"
+
+// keywordLocations records the keyword location of the errors of a validation:
+// the JSON pointer of the keyword that raised them, along the evaluation path
+// from the root schema, $refs included.
+type keywordLocations struct {
+	mu        sync.Mutex
+	locations map[SchemaError]string
+}
+
+func newKeywordLocations() *keywordLocations {
+	return &keywordLocations{locations: make(map[SchemaError]string)}
+}
+
+func (l *keywordLocations) add(e SchemaError, location string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.locations[e] = location
+}
+
+func (l *keywordLocations) get(e SchemaError) string {
+	if l == nil {
+		return ""
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.locations[e]
+}
+
+// locate records the keyword location of e, raised by the schema of b. The
+// errors of the probes are not reported, so they are not recorded.
+func (b *baseConstraint) locate(e SchemaError) {
+	if b.opts == nil || b.opts.locations == nil || b.opts.probing {
+		return
+	}
+	keyword := errorKeyword(e.Code())
+	if keyword == "" {
+		return
+	}
+	b.opts.locations.add(e, b.location+"/"+escapePointer(keyword))
+}
+
+// pointer returns the JSON pointer made of the reference tokens.
+func pointer(tokens ...string) string {
+	p := ""
+	for _, token := range tokens {
+		p += "/" + escapePointer(token)
+	}
+	return p
+}
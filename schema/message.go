@@ -0,0 +1,287 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is the locale used when a message is missing in the requested one.
+const DefaultLocale = "en"
+
+// ErrorFormatter turns a SchemaError into a human readable message.
+type ErrorFormatter interface {
+	Format(e SchemaError) string
+}
+
+// englishMessages are the default message templates. A template can refer to
+// the error's params and to {{.path}} and {{.code}}.
+var englishMessages = map[ErrorCode]string{
+	NumericMultipleOfError:       "must be a multiple of {{.divisor}}",
+	NumericTypeMismatchError:     "must be a number",
+	NumericMaximumError:          "must be less than or equal to {{.limit}}",
+	NumericExclusiveMaximumError: "must be less than {{.limit}}",
+	NumericMinimumError:          "must be greater than or equal to {{.limit}}",
+	NumericExclusiveMinimumError: "must be greater than {{.limit}}",
+
+	StringTypeMismatchError: "must be a string",
+	StringMinLengthError:    "must be at least {{.limit}} characters long",
+	StringMaxLengthError:    "must be at most {{.limit}} characters long",
+	StringPatternError:      "must match the pattern {{.pattern}}",
+	InvalidPatternError:     "can not be validated, the pattern {{.pattern}} is invalid: {{.error}}",
+	StringInvalidUTF8Error:  "must be valid UTF-8",
+
+	ArrayTypeMismatchError:   "must be an array",
+	ArrayMaxItemError:        "must contain at most {{.limit}} items",
+	ArrayMinItemError:        "must contain at least {{.limit}} items",
+	ArrayUniqueItemError:     "must not contain duplicate items",
+	ArrayAdditionalItemError: "additional items are not allowed",
+	ArrayItemError:           "contains an invalid item",
+
+	ObjectMaxPropertiesError:      "must have at most {{.limit}} properties",
+	ObjectMinPropertiesError:      "must have at least {{.limit}} properties",
+	ObjectRequiredPropertiesError: "is missing the required property {{.property}}",
+	ObjectUndefinedPropertyError:  "is not an allowed property",
+
+	TypeError:          "has an unsupported type",
+	TypeNotMatchError:  "must be of type {{.expected}}",
+	TypesNotMatchError: "must be one of the types {{.expected}}",
+
+	EnumError: "must be one of the allowed values",
+
+	AllOfError: "must match all of the schemas in allOf",
+	AnyOfError: "must match at least one of the schemas in anyOf",
+	OneOfError: "must match exactly one of the schemas in oneOf",
+	NotError:   "must not match the schema in not",
+
+	UndefinedTypeError: "has an undefined type",
+
+	InvalidJSONError: "is not valid JSON: {{.error}}",
+
+	ValidationCanceledError: "was not completely validated, the validation was canceled",
+	ValidationDeadlineError: "was not completely validated, the validation timed out",
+
+	RefError:            "can not be validated, the $ref {{.ref}} can not be resolved: {{.error}}",
+	MaxRefDepthError:    "can not be validated, more than {{.limit}} $refs are followed in a row to {{.ref}}",
+	MaxDepthError:       "is nested deeper than the limit of {{.limit}}",
+	MaxEvaluationsError: "was not completely validated, more than {{.limit}} subschemas were evaluated",
+
+	InvalidReplacementError: "can not be validated, its replacement is not a JSON value: {{.error}}",
+	MaxReplacementsError:    "can not be validated, it was replaced more than {{.limit}} times in a row",
+}
+
+// Catalog holds message templates per locale, keyed by ErrorCode.
+type Catalog struct {
+	mu      sync.RWMutex
+	locales map[string]map[ErrorCode]*template.Template
+}
+
+// DefaultCatalog is the catalog used by WithLocale and RegisterLocale.
+var DefaultCatalog = NewCatalog()
+
+// NewCatalog returns a catalog that contains the English default messages.
+func NewCatalog() *Catalog {
+	c := &Catalog{
+		locales: make(map[string]map[ErrorCode]*template.Template),
+	}
+	if err := c.Register(DefaultLocale, englishMessages); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// RegisterLocale adds messages for locale to the DefaultCatalog.
+func RegisterLocale(locale string, messages map[ErrorCode]string) error {
+	return DefaultCatalog.Register(locale, messages)
+}
+
+// Register adds messages for locale. Messages already registered for the same
+// locale and code are replaced, the others are kept.
+func (c *Catalog) Register(locale string, messages map[ErrorCode]string) error {
+	compiled := make(map[ErrorCode]*template.Template, len(messages))
+	for code, text := range messages {
+		t, err := template.New(string(code)).Parse(text)
+		if err != nil {
+			return fmt.Errorf("Invalid message for %s in locale %s: %s", code, locale, err)
+		}
+		compiled[code] = t
+	}
+
+	locale = normalizeLocale(locale)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.locales[locale] == nil {
+		c.locales[locale] = make(map[ErrorCode]*template.Template)
+	}
+	for code, t := range compiled {
+		c.locales[locale][code] = t
+	}
+	return nil
+}
+
+// RegisterFile loads messages for locale from a JSON or YAML file, depending on
+// its extension. The file must be an object mapping error codes to templates.
+func (c *Catalog) RegisterFile(locale string, filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	messages := make(map[ErrorCode]string)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		err = json.Unmarshal(data, &messages)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &messages)
+	default:
+		return fmt.Errorf("Unsupported message file %s", filename)
+	}
+	if err != nil {
+		return fmt.Errorf("Invalid message file %s: %s", filename, err)
+	}
+
+	return c.Register(locale, messages)
+}
+
+// Formatter returns an ErrorFormatter for locale. Messages missing in locale are
+// looked up in its base language ("de" for "de-AT") and then in DefaultLocale.
+func (c *Catalog) Formatter(locale string) ErrorFormatter {
+	return &catalogFormatter{catalog: c, locale: normalizeLocale(locale)}
+}
+
+func (c *Catalog) lookup(locale string, code ErrorCode) *template.Template {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, l := range fallbackLocales(locale) {
+		if t, ok := c.locales[l][code]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
+type catalogFormatter struct {
+	catalog *Catalog
+	locale  string
+}
+
+func (f *catalogFormatter) Format(e SchemaError) string {
+	t := f.catalog.lookup(f.locale, e.Code())
+	if t == nil {
+		return string(e.Code())
+	}
+
+	data := map[string]interface{}{
+		"path": e.Path(),
+		"code": e.Code(),
+	}
+	for k, v := range e.Params() {
+		data[k] = v
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return string(e.Code())
+	}
+	return buf.String()
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(locale, "_", "-", -1))
+}
+
+func fallbackLocales(locale string) []string {
+	locales := []string{locale}
+	if i := strings.Index(locale, "-"); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	return append(locales, DefaultLocale)
+}
+
+// keywordErrorCodes maps the keywords of the errorMessage keyword to the error
+// codes they raise.
+var keywordErrorCodes = map[string][]ErrorCode{
+	"multipleOf":           {NumericMultipleOfError},
+	"maximum":              {NumericMaximumError},
+	"exclusiveMaximum":     {NumericExclusiveMaximumError},
+	"minimum":              {NumericMinimumError},
+	"exclusiveMinimum":     {NumericExclusiveMinimumError},
+	"maxLength":            {StringMaxLengthError},
+	"minLength":            {StringMinLengthError},
+	"pattern":              {StringPatternError, InvalidPatternError},
+	"maxItems":             {ArrayMaxItemError},
+	"minItems":             {ArrayMinItemError},
+	"uniqueItems":          {ArrayUniqueItemError},
+	"additionalItems":      {ArrayAdditionalItemError},
+	"items":                {ArrayItemError},
+	"maxProperties":        {ObjectMaxPropertiesError},
+	"minProperties":        {ObjectMinPropertiesError},
+	"required":             {ObjectRequiredPropertiesError},
+	"additionalProperties": {ObjectUndefinedPropertyError},
+	"type":                 {TypeError, TypeNotMatchError, TypesNotMatchError},
+	"enum":                 {EnumError},
+	"allOf":                {AllOfError},
+	"anyOf":                {AnyOfError},
+	"oneOf":                {OneOfError},
+	"not":                  {NotError},
+	"$ref":                 {RefError, MaxRefDepthError},
+}
+
+// applyErrorMessages sets the messages declared with the errorMessage keyword of
+// b's schema on the errors raised by that schema since index start. Errors of
+// nested subschemas have already been scoped by their own constraint.
+func (b *baseConstraint) applyErrorMessages(v interface{}, start int) {
+	message, keywordMessages, _ := b.schema.ErrorMessage()
+
+	for _, e := range b.errors[start:] {
+		se, ok := e.(*schemaError)
+		if !ok || se.scoped {
+			continue
+		}
+		se.scoped = true
+
+		msg := message
+		for keyword, m := range keywordMessages {
+			for _, code := range keywordErrorCodes[keyword] {
+				if code == se.code {
+					msg = m
+				}
+			}
+		}
+		if msg != "" {
+			se.message = interpolateValue(msg, v)
+		}
+	}
+}
+
+// interpolateValue replaces ${0} in msg with the instance value.
+func interpolateValue(msg string, v interface{}) string {
+	if !strings.Contains(msg, "${0}") {
+		return msg
+	}
+
+	var str string
+	switch v.(type) {
+	case string:
+		str = v.(string)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			str = fmt.Sprint(v)
+		} else {
+			str = string(b)
+		}
+	}
+
+	return strings.Replace(msg, "${0}", str, -1)
+}
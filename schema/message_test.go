@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogFormatter(t *testing.T) {
+	c := NewCatalog()
+	assert.NoError(t, c.Register("de", map[ErrorCode]string{
+		StringMaxLengthError: "darf höchstens {{.limit}} Zeichen lang sein",
+	}))
+
+	tests := []struct {
+		locale   string
+		err      SchemaError
+		expected string
+	}{
+		{
+			locale:   "en",
+			err:      newError(StringMaxLengthError, "a").withParam("limit", 3),
+			expected: "must be at most 3 characters long",
+		},
+		{
+			locale:   "de",
+			err:      newError(StringMaxLengthError, "a").withParam("limit", 3),
+			expected: "darf höchstens 3 Zeichen lang sein",
+		},
+		{
+			locale:   "de_AT",
+			err:      newError(StringMaxLengthError, "a").withParam("limit", 3),
+			expected: "darf höchstens 3 Zeichen lang sein",
+		},
+		{
+			locale:   "de",
+			err:      newError(EnumError, "a"),
+			expected: "must be one of the allowed values",
+		},
+		{
+			locale:   "fr",
+			err:      newError(ObjectRequiredPropertiesError, "a").withParam("property", "b"),
+			expected: "is missing the required property b",
+		},
+		{
+			locale:   "en",
+			err:      newError(ErrorCode("unknown"), "a"),
+			expected: "unknown",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, c.Formatter(test.locale).Format(test.err))
+	}
+}
+
+func TestCatalogRegisterInvalidTemplate(t *testing.T) {
+	c := NewCatalog()
+	assert.Error(t, c.Register("de", map[ErrorCode]string{
+		EnumError: "{{.limit",
+	}))
+}
+
+func TestCatalogRegisterFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "messages")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	jsonFile := filepath.Join(dir, "fr.json")
+	data, _ := json.Marshal(map[string]string{
+		string(StringMinLengthError): "doit contenir au moins {{.limit}} caractères",
+	})
+	assert.NoError(t, ioutil.WriteFile(jsonFile, data, 0644))
+
+	yamlFile := filepath.Join(dir, "ja.yaml")
+	assert.NoError(t, ioutil.WriteFile(yamlFile, []byte("minLength: \"{{.limit}}文字以上である必要があります\"\n"), 0644))
+
+	c := NewCatalog()
+	assert.NoError(t, c.RegisterFile("fr", jsonFile))
+	assert.NoError(t, c.RegisterFile("ja", yamlFile))
+	assert.Error(t, c.RegisterFile("it", filepath.Join(dir, "it.txt")))
+
+	e := newError(StringMinLengthError, "a").withParam("limit", 2)
+	assert.Equal(t, "doit contenir au moins 2 caractères", c.Formatter("fr").Format(e))
+	assert.Equal(t, "2文字以上である必要があります", c.Formatter("ja").Format(e))
+}
+
+func TestErrorMessageKeyword(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"type": "array",
+		"maxItems": 2,
+		"items": {
+			"type": "string",
+			"pattern": "^[0-9]{5}$",
+			"minLength": 5,
+			"errorMessage": {
+				"pattern": "Postcode must be 5 digits, got ${0}"
+			}
+		},
+		"errorMessage": "Too many postcodes"
+	}
+	`)
+	assert.NoError(t, err)
+
+	res := NewValidator(s, WithErrorMessageKeyword()).Validate([]interface{}{"1234", "1234", "1234"})
+	assert.ElementsMatch(t, []string{
+		"$[0]: Postcode must be 5 digits, got 1234",
+		"$[0]: must be at least 5 characters long",
+		"$[1]: Postcode must be 5 digits, got 1234",
+		"$[1]: must be at least 5 characters long",
+		"$[2]: Postcode must be 5 digits, got 1234",
+		"$[2]: must be at least 5 characters long",
+		"$: Too many postcodes",
+	}, res.Messages())
+
+	// the keyword is opt-in
+	res = NewValidator(s).Validate([]interface{}{"1234"})
+	assert.ElementsMatch(t, []string{
+		"$[0]: must be at least 5 characters long",
+		"$[0]: must match the pattern ^[0-9]{5}$",
+	}, res.Messages())
+}
+
+func TestInterpolateValue(t *testing.T) {
+	assert.Equal(t, "got abc", interpolateValue("got ${0}", "abc"))
+	assert.Equal(t, "got 12", interpolateValue("got ${0}", json.Number("12")))
+	assert.Equal(t, `got {"a":true}`, interpolateValue("got ${0}", map[string]interface{}{"a": true}))
+	assert.Equal(t, "no value", interpolateValue("no value", "abc"))
+}
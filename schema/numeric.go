@@ -22,27 +22,30 @@ func (constraint *NumericConstraint) Validate(v interface{}, path string) {
 
 	if divided, ok := schema.MultipleOf(); ok {
 		if math.Mod(f, divided) != float64(0) {
-			constraint.addError(newError(NumericMultipleOfError, path))
+			constraint.addError(newError(NumericMultipleOfError, path).withParam("divisor", divided))
 		}
+		constraint.evaluated(path, "multipleOf")
 	}
 
 	if max, ok := schema.Maximum(); ok {
 		if f > max {
-			constraint.addError(newError(NumericMaximumError, path))
+			constraint.addError(newError(NumericMaximumError, path).withParam("limit", max))
 		}
 
 		if schema.ExclusiveMaximum() && f == max {
-			constraint.addError(newError(NumericExclusiveMaximumError, path))
+			constraint.addError(newError(NumericExclusiveMaximumError, path).withParam("limit", max))
 		}
+		constraint.evaluated(path, "maximum")
 	}
 
 	if min, ok := schema.Minimum(); ok {
 		if f < min {
-			constraint.addError(newError(NumericMinimumError, path))
+			constraint.addError(newError(NumericMinimumError, path).withParam("limit", min))
 		}
 
 		if schema.ExclusiveMinimum() && f == min {
-			constraint.addError(newError(NumericExclusiveMinimumError, path))
+			constraint.addError(newError(NumericExclusiveMinimumError, path).withParam("limit", min))
 		}
+		constraint.evaluated(path, "minimum")
 	}
 }
@@ -23,7 +23,7 @@ func TestNumericConstraint(t *testing.T) {
 			},
 
 			expected: []SchemaError{
-				&schemaError{NumericMultipleOfError, "a"},
+				newError(NumericMultipleOfError, "a").withParam("divisor", float64(3)),
 			},
 		},
 		{
@@ -35,8 +35,8 @@ func TestNumericConstraint(t *testing.T) {
 			},
 
 			expected: []SchemaError{
-				&schemaError{NumericMultipleOfError, "a"},
-				&schemaError{NumericMinimumError, "a"},
+				newError(NumericMultipleOfError, "a").withParam("divisor", 3.1),
+				newError(NumericMinimumError, "a").withParam("limit", float64(90)),
 			},
 		},
 	}
@@ -1,12 +1,12 @@
 package schema
+
 import (
 	"fmt"
-	"regexp"
+	"sort"
 )
 
 type ObjectConstraint struct {
-	schema       Schema
-	propPatterns []*regexp.Regexp
+	schema Schema
 	baseConstraint
 }
 
@@ -18,23 +18,39 @@ func NewObjectConstraint(s Schema) *ObjectConstraint {
 
 func (o *ObjectConstraint) Validate(v interface{}, path string) {
 	obj := v.(map[string]interface{})
-
-	o.validateMaxProperties(obj, path)
-	o.validateMinProperties(obj, path)
-	o.validateRequired(obj, path)
+	o.applyDefaults(obj)
+
+	validations := []struct {
+		keyword  string
+		validate func(map[string]interface{}, string)
+	}{
+		{"maxProperties", o.validateMaxProperties},
+		{"minProperties", o.validateMinProperties},
+		{"required", o.validateRequired},
+		{"properties", o.validateProperties},
+	}
+	for _, validation := range validations {
+		if o.stopped() {
+			return
+		}
+		validation.validate(obj, path)
+		if _, ok := o.schema[validation.keyword]; ok {
+			o.evaluated(path, validation.keyword)
+		}
+	}
 }
 
 func (o *ObjectConstraint) validateMaxProperties(obj map[string]interface{}, path string) {
 	max, exist := o.schema.MaxProperties()
 	if exist && len(obj) > max {
-		o.addError(newError(ObjectMaxPropertiesError, path))
+		o.addError(newError(ObjectMaxPropertiesError, path).withParam("limit", max))
 	}
 }
 
 func (o *ObjectConstraint) validateMinProperties(obj map[string]interface{}, path string) {
 	min, exist := o.schema.MinProperties()
 	if exist && len(obj) < min {
-		o.addError(newError(ObjectMinPropertiesError, path))
+		o.addError(newError(ObjectMinPropertiesError, path).withParam("limit", min))
 	}
 }
 
@@ -43,73 +59,65 @@ func (o *ObjectConstraint) validateRequired(obj map[string]interface{}, path str
 	if exist {
 		for _, prop := range required {
 			if _, ok := obj[prop]; !ok {
-				o.addError(newError(ObjectRequiredPropertiesError, path))
+				o.addError(newError(ObjectRequiredPropertiesError, path).withParam("property", prop))
 			}
 		}
 	}
 }
 
+// validateProperties validates every property against the schemas of
+// "properties", "patternProperties" and "additionalProperties" that apply to it.
 func (o *ObjectConstraint) validateProperties(obj map[string]interface{}, path string) {
-	propSchema, exist := o.schema.Properties()
-	if !exist {
+	_, hasProperties := o.schema.Properties()
+	_, hasPatternProperties := o.schema.PatternProperties()
+	_, _, hasAddition := o.schema.AdditionalProperties()
+	if !hasProperties && !hasPatternProperties && !hasAddition {
 		return
 	}
 
-	patternProperties, hasPatternProperties := o.schema.PatternProperties()
-	additionSchema, allowAddition, hasAddition := o.schema.AdditionalProperties()
-
-	for prop, val := range obj {
-		subPath := fmt.Sprintf("%s.%s", path, prop)
-
-		var s Schema
-
-		// defined property
-		if s, ok := propSchema[prop]; ok {
-			c := NewBaseConstraint(s)
-			c.Validate(val, subPath)
-			o.addErrors(c.Errors())
-			continue
-		}
-
-		// try pattern property
-		if hasPatternProperties && patternProperties.match(prop, val) {
-			continue
-		}
-
-		// no additional
-		if !hasAddition || (hasAddition && !allowAddition ){
-			o.addError(newError(ObjectUndefinedPropertyError, subPath))
-			continue
-		}
-
-		if hasAddition && allowAddition {
-			continue
-		}
-
-		if hasAddition &&
+	// sorted, so that the errors come in a stable order
+	props := make([]string, 0, len(obj))
+	for prop := range obj {
+		props = append(props, prop)
 	}
-}
-
-func (o *ObjectConstraint) tryMatchPattern(p string) bool {
-	if len(o.propPatterns) == 0 {
-		return false
+	sort.Strings(props)
+
+	if o.parallel(len(props)) {
+		o.validateParallel(len(props), func(i int) []SchemaError {
+			c := o.child(nil)
+			o.validateProperty(c, obj, props[i], path)
+			return c.Errors()
+		})
+		return
 	}
 
-	for _, pp := range o.propPatterns {
-		if pp.MatchString(p) {
-			return true
+	for _, prop := range props {
+		if o.stopped() {
+			return
 		}
+		o.validateProperty(&o.baseConstraint, obj, prop, path)
 	}
-
-	return false
 }
 
+// validateProperty validates the property prop of obj, and adds the errors to into.
+func (o *ObjectConstraint) validateProperty(into *baseConstraint, obj map[string]interface{}, prop string, path string) {
+	subPath := fmt.Sprintf("%s.%s", path, prop)
 
-func (o *ObjectConstraint) validatePatternProperties(obj map[string]interface{}, path string) {
-
-}
-
-func (o *ObjectConstraint) validateAdditionalProperties(obj map[string]interface{}, path string) {
+	schemas, tokens, allowed := o.schema.propertySchemas(prop, func(pattern string) bool {
+		matched, _ := into.match(pattern, prop, subPath)
+		return matched
+	})
+	if !allowed {
+		into.addError(newError(ObjectUndefinedPropertyError, subPath))
+		return
+	}
 
+	for i, s := range schemas {
+		c := o.child(s, tokens[i]...)
+		c.Validate(obj[prop], subPath)
+		into.addErrors(c.Errors())
+		if c.replaced {
+			obj[prop] = c.replacement
+		}
+	}
 }
-
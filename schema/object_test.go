@@ -1,8 +1,9 @@
 package schema
+
 import (
-	"testing"
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
+	"testing"
 )
 
 func TestObjectMaxMinRequired(t *testing.T) {
@@ -22,14 +23,14 @@ func TestObjectMaxMinRequired(t *testing.T) {
 				"required": []interface{}{
 					"a", "b",
 				},
-//				"properties": map[string]interface{}{
-//					"a": map[string]interface{}{
-//						"type": "integer",
-//					},
-//					"b": map[string]interface{}{
-//						"type": "integer",
-//					},
-//				},
+				//				"properties": map[string]interface{}{
+				//					"a": map[string]interface{}{
+				//						"type": "integer",
+				//					},
+				//					"b": map[string]interface{}{
+				//						"type": "integer",
+				//					},
+				//				},
 			},
 			expected: nil,
 		},
@@ -43,24 +44,24 @@ func TestObjectMaxMinRequired(t *testing.T) {
 				"required": []interface{}{
 					"a", "b",
 				},
-//				"properties": map[string]interface{}{
-//					"a": map[string]interface{}{
-//						"type": "integer",
-//					},
-//					"b": map[string]interface{}{
-//						"type": "integer",
-//					},
-//				},
+				//				"properties": map[string]interface{}{
+				//					"a": map[string]interface{}{
+				//						"type": "integer",
+				//					},
+				//					"b": map[string]interface{}{
+				//						"type": "integer",
+				//					},
+				//				},
 			},
 			expected: []SchemaError{
-				newError(ObjectMinPropertiesError, "p"),
-				newError(ObjectRequiredPropertiesError, "p"),
+				newError(ObjectMinPropertiesError, "p").withParam("limit", 2),
+				newError(ObjectRequiredPropertiesError, "p").withParam("property", "b"),
 			},
 		},
 	}
 
 	path := "p"
-	for _, test :=range tests {
+	for _, test := range tests {
 		c := NewObjectConstraint(test.schema)
 		c.Validate(test.obj, path)
 
@@ -68,4 +69,75 @@ func TestObjectMaxMinRequired(t *testing.T) {
 	}
 }
 
+func TestObjectProperties(t *testing.T) {
+	tests := []struct {
+		obj      map[string]interface{}
+		schema   Schema
+		expected []SchemaError
+	}{
+		{
+			obj: map[string]interface{}{
+				"a":   json.Number("5"),
+				"a1b": "str",
+				"c":   true,
+			},
+			schema: Schema{
+				"properties": map[string]interface{}{
+					"a": map[string]interface{}{
+						"type": "integer",
+					},
+				},
+				"patternProperties": map[string]interface{}{
+					"a[0-9]b": map[string]interface{}{
+						"type": "integer",
+					},
+				},
+			},
+			expected: []SchemaError{
+				newError(TypeNotMatchError, "p.a1b").withParam("expected", JsonInteger),
+			},
+		},
+		{
+			obj: map[string]interface{}{
+				"a": json.Number("5"),
+				"b": json.Number("6"),
+				"c": "str",
+			},
+			schema: Schema{
+				"properties": map[string]interface{}{
+					"a": map[string]interface{}{
+						"type": "integer",
+					},
+				},
+				"additionalProperties": map[string]interface{}{
+					"type": "string",
+				},
+			},
+			expected: []SchemaError{
+				newError(TypeNotMatchError, "p.b").withParam("expected", JsonString),
+			},
+		},
+		{
+			obj: map[string]interface{}{
+				"a": json.Number("5"),
+				"b": json.Number("6"),
+			},
+			schema: Schema{
+				"properties": map[string]interface{}{
+					"a": map[string]interface{}{},
+				},
+				"additionalProperties": false,
+			},
+			expected: []SchemaError{
+				newError(ObjectUndefinedPropertyError, "p.b"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c := NewObjectConstraint(test.schema)
+		c.Validate(test.obj, "p")
 
+		assert.Equal(t, test.expected, c.Errors())
+	}
+}
@@ -0,0 +1,334 @@
+package schema
+
+import "context"
+
+// Option configures a Validator, or a single call to Validator.Validate.
+type Option func(*options)
+
+type options struct {
+	formatter     ErrorFormatter
+	errorMessages bool
+	failFast      bool
+	maxErrors     int
+	emitter       EventEmitter
+	interaction   *interaction
+	lineWorkers   int
+	workers       int
+
+	parallelThreshold int
+	ctx               context.Context
+	// interrupted is set to 1 once the validation stopped because ctx was done.
+	interrupted *int32
+	// errorCount counts the errors raised by the validation, for failFast and
+	// maxErrors.
+	errorCount *int64
+
+	loader     Loader
+	remoteRefs bool
+	fileRefs   bool
+	schemas    map[string]Schema
+	// refs resolves the $refs, it is set by the Validator.
+	refs *registry
+
+	regexpEngine RegexpEngine
+	// regexps compiles the patterns, it is set by the Validator.
+	regexps *regexpCache
+
+	maxRefDepth      int
+	maxDepth         int
+	maxEvaluations   int
+	maxPatternLength int
+	// evaluations counts the subschemas evaluated, and exhausted is set to 1
+	// once there were more than maxEvaluations.
+	evaluations *int64
+	exhausted   *int32
+
+	// locations records the keyword locations of the errors.
+	locations *keywordLocations
+
+	// lintRules are the only rules Lint checks if set, except lintDisabled.
+	lintRules    map[LintRule]bool
+	lintDisabled map[LintRule]bool
+
+	// noCyclicRefs makes Dereference fail on the cyclic $refs.
+	noCyclicRefs bool
+
+	// lenientYAML converts the YAML values that are not JSON values to strings.
+	lenientYAML bool
+
+	// graphemeClusters counts the string lengths in grapheme clusters.
+	graphemeClusters bool
+	defaults         bool
+	coercion         bool
+	separator        string
+	removeAdditional bool
+
+	// probing is set for the subschemas of allOf, anyOf, oneOf and not.
+	probing bool
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		formatter:   DefaultCatalog.Formatter(DefaultLocale),
+		interrupted: new(int32),
+		errorCount:  new(int64),
+		maxRefDepth: DefaultMaxRefDepth,
+		evaluations: new(int64),
+		exhausted:   new(int32),
+		locations:   newKeywordLocations(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithLocale formats the error messages with the DefaultCatalog messages of locale.
+func WithLocale(locale string) Option {
+	return func(o *options) {
+		o.formatter = DefaultCatalog.Formatter(locale)
+	}
+}
+
+// WithFormatter formats the error messages with f.
+func WithFormatter(f ErrorFormatter) Option {
+	return func(o *options) {
+		o.formatter = f
+	}
+}
+
+// WithErrorMessageKeyword enables the errorMessage keyword, which replaces the
+// default message of the errors raised by the subschema declaring it.
+func WithErrorMessageKeyword() Option {
+	return func(o *options) {
+		o.errorMessages = true
+	}
+}
+
+// WithGraphemeClusters counts the length of strings for maxLength and minLength
+// in grapheme clusters, i.e. user-perceived characters, instead of code points.
+func WithGraphemeClusters() Option {
+	return func(o *options) {
+		o.graphemeClusters = true
+	}
+}
+
+// WithDefaults fills the missing properties of the objects with the default of
+// their schema in properties before validating them, including the objects of
+// the defaults. The instance is modified in place, and returned as Result.Value.
+// The stream validations do not apply defaults.
+func WithDefaults() Option {
+	return func(o *options) {
+		o.defaults = true
+	}
+}
+
+// WithCoercion converts the strings to the type of their schema before validating
+// them: "42" to an integer or a number, "true" and "false" to booleans, "" to
+// null, and strings to arrays of the strings between separator, "" meaning
+// DefaultArraySeparator. The first type of the schema the string can represent
+// is used, and strings are kept if a string is allowed. The instance is modified
+// in place, and returned as Result.Value.
+func WithCoercion(separator string) Option {
+	return func(o *options) {
+		if separator == "" {
+			separator = DefaultArraySeparator
+		}
+		o.coercion = true
+		o.separator = separator
+	}
+}
+
+// WithRemoveAdditional validates a copy of the instance without the properties
+// its schema does not declare, see Validator.Sanitize. The copy is returned as
+// Result.Value, and the paths of the removed properties as Result.Removed. The
+// stream validations do not remove properties.
+func WithRemoveAdditional() Option {
+	return func(o *options) {
+		o.removeAdditional = true
+	}
+}
+
+// WithFailFast stops the validation at the first error.
+func WithFailFast() Option {
+	return func(o *options) {
+		o.failFast = true
+	}
+}
+
+// WithMaxErrors stops the validation once n errors are collected. A value of 0
+// collects every error.
+func WithMaxErrors(n int) Option {
+	return func(o *options) {
+		o.maxErrors = n
+	}
+}
+
+// WithEventEmitter raises the validation events on e.
+func WithEventEmitter(e EventEmitter) Option {
+	return func(o *options) {
+		o.emitter = e
+	}
+}
+
+// WithInteractive asks r how to go on after every error, see Resolver.
+func WithInteractive(r Resolver) Option {
+	return func(o *options) {
+		o.interaction = &interaction{resolver: r}
+	}
+}
+
+// WithLineWorkers validates up to n lines in parallel in Validator.ValidateLines.
+// The results are still reported in the order of the lines. The lines of a
+// validation given WithEventEmitter are validated one at a time.
+func WithLineWorkers(n int) Option {
+	return func(o *options) {
+		o.lineWorkers = n
+	}
+}
+
+// WithParallel validates the items of arrays and the properties of objects with
+// up to workers goroutines, once there are at least threshold of them. A
+// threshold of 0 means DefaultParallelThreshold. The errors are reported in the
+// same order as in a sequential validation. With WithFailFast or WithMaxErrors,
+// the workers stop as soon as enough errors were found by any of them, so the
+// errors reported are not always the first ones of a sequential validation. A
+// validation given WithEventEmitter stays sequential.
+func WithParallel(workers int, threshold int) Option {
+	return func(o *options) {
+		o.workers = workers
+		o.parallelThreshold = threshold
+	}
+}
+
+// WithContext stops the validation once ctx is done, see Validator.ValidateContext.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithLoader loads the documents referenced by $ref with l instead of the
+// DefaultLoader. The documents are still only loaded if WithRemoteRefs or
+// WithFileRefs allows it. It only applies when given to NewValidator or Compile.
+func WithLoader(l Loader) Option {
+	return func(o *options) {
+		o.loader = l
+	}
+}
+
+// WithRemoteRefs allows loading the documents referenced by $ref from any other
+// scheme than file, such as http and https. By default, a $ref can only
+// reference the schema itself and the documents added with WithSchema, as the
+// schemas may be untrusted. It only applies when given to NewValidator or
+// Compile.
+func WithRemoteRefs() Option {
+	return func(o *options) {
+		o.remoteRefs = true
+	}
+}
+
+// WithFileRefs allows loading the documents referenced by $ref from the file
+// system, that is the file URIs and the paths, including the relative $refs of a
+// document without a base URI. It only applies when given to NewValidator or
+// Compile.
+func WithFileRefs() Option {
+	return func(o *options) {
+		o.fileRefs = true
+	}
+}
+
+// WithSchema adds s as the document at uri, so the $refs to uri do not load it.
+// It only applies when given to NewValidator or Compile.
+func WithSchema(uri string, s Schema) Option {
+	return func(o *options) {
+		if o.schemas == nil {
+			o.schemas = make(map[string]Schema)
+		}
+		o.schemas[uri] = s
+	}
+}
+
+// WithRegexpEngine compiles the patterns of the pattern and patternProperties
+// keywords with e instead of the DefaultRegexpEngine. It only applies when given
+// to NewValidator or Compile.
+func WithRegexpEngine(e RegexpEngine) Option {
+	return func(o *options) {
+		o.regexpEngine = e
+	}
+}
+
+// WithMaxRefDepth limits the number of $refs followed in a row without entering
+// a value of the instance, which bounds the circular $refs. The default is
+// DefaultMaxRefDepth.
+func WithMaxRefDepth(n int) Option {
+	return func(o *options) {
+		o.maxRefDepth = n
+	}
+}
+
+// WithMaxDepth rejects the instances whose arrays and objects are nested deeper
+// than n, without validating them. A value of 0 means no limit.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithMaxEvaluations stops the validation once n subschemas were evaluated, and
+// makes Dereference fail once it copied n subschemas. A value of 0 means no
+// limit for the validation, and DefaultMaxDereferencedSchemas for Dereference.
+func WithMaxEvaluations(n int) Option {
+	return func(o *options) {
+		o.maxEvaluations = n
+	}
+}
+
+// WithMaxPatternLength makes Compile reject the schemas with a pattern or a
+// patternProperties key longer than n bytes, and a Validator report them as
+// invalid patterns without compiling them. A value of 0 means no limit. It only
+// applies when given to NewValidator or Compile.
+func WithMaxPatternLength(n int) Option {
+	return func(o *options) {
+		o.maxPatternLength = n
+	}
+}
+
+// WithLintRules makes Lint check only rules.
+func WithLintRules(rules ...LintRule) Option {
+	return func(o *options) {
+		o.lintRules = make(map[LintRule]bool)
+		for _, rule := range rules {
+			o.lintRules[rule] = true
+		}
+	}
+}
+
+// WithoutLintRules makes Lint skip rules.
+func WithoutLintRules(rules ...LintRule) Option {
+	return func(o *options) {
+		if o.lintDisabled == nil {
+			o.lintDisabled = make(map[LintRule]bool)
+		}
+		for _, rule := range rules {
+			o.lintDisabled[rule] = true
+		}
+	}
+}
+
+// WithoutCyclicRefs makes Dereference return an error on the cyclic $refs
+// instead of keeping them.
+func WithoutCyclicRefs() Option {
+	return func(o *options) {
+		o.noCyclicRefs = true
+	}
+}
+
+// WithLenientYAML converts the keys of the YAML documents that are not strings,
+// such as numbers and booleans, and their timestamps, binary values and custom
+// tags to strings, with their text in the document, instead of rejecting them.
+func WithLenientYAML() Option {
+	return func(o *options) {
+		o.lenientYAML = true
+	}
+}
@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultParallelThreshold is the number of items or properties from which they
+// are validated in parallel, when WithParallel is given a threshold of 0.
+const DefaultParallelThreshold = 1000
+
+// parallel reports whether n items or properties are validated in parallel.
+// Interactive validations are always sequential, as the decisions are taken one
+// error at a time, and so are the ones coercing values, which are written back.
+// The ones raising events are sequential too, so the event handlers are never
+// called concurrently and get the events in the order of the document.
+func (b *baseConstraint) parallel(n int) bool {
+	if b.opts == nil || b.opts.workers <= 1 || b.opts.interaction != nil || b.opts.coercion || b.opts.emitter != nil {
+		return false
+	}
+
+	threshold := b.opts.parallelThreshold
+	if threshold <= 0 {
+		threshold = DefaultParallelThreshold
+	}
+	return n >= threshold
+}
+
+// validateParallel calls validate for 0..n-1 from the workers, and adds the
+// errors to b in the order of the indexes, so they do not depend on scheduling.
+// In fail-fast mode, the indexes after the first invalid one are not validated,
+// and once the validation has all the errors it may collect, no more index is.
+// If the context of the validation is done, the remaining indexes are not
+// validated.
+func (b *baseConstraint) validateParallel(n int, validate func(i int) []SchemaError) {
+	results := make([][]SchemaError, n)
+	next := int64(-1)
+	firstInvalid := int64(n)
+
+	var wg sync.WaitGroup
+	for w := 0; w < b.opts.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				// indexes are handed out in increasing order
+				i := atomic.AddInt64(&next, 1)
+				if i >= int64(n) {
+					return
+				}
+				if b.opts.failFast && i > atomic.LoadInt64(&firstInvalid) {
+					return
+				}
+				if b.done() || b.canceled() || b.exhausted() {
+					return
+				}
+
+				results[i] = validate(int(i))
+
+				if b.opts.failFast && len(results[i]) > 0 {
+					for {
+						first := atomic.LoadInt64(&firstInvalid)
+						if i >= first || atomic.CompareAndSwapInt64(&firstInvalid, first, i) {
+							break
+						}
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, errs := range results {
+		b.addErrors(errs)
+	}
+}
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParallel(t *testing.T) {
+	s := Schema{
+		"items": map[string]interface{}{
+			"type": "object",
+			"additionalProperties": map[string]interface{}{
+				"type":    "integer",
+				"maximum": json.Number("50"),
+			},
+		},
+	}
+
+	var items []interface{}
+	for i := 0; i < 200; i++ {
+		obj := make(map[string]interface{})
+		for j := 0; j < 100; j++ {
+			obj[fmt.Sprintf("p%03d", j)] = json.Number(fmt.Sprintf("%d", (i+j)%100))
+		}
+		items = append(items, obj)
+	}
+
+	expected := NewValidator(s).Validate(items).Errors
+	assert.NotEmpty(t, expected)
+
+	v := NewValidator(s, WithParallel(4, 10))
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, expected, v.Validate(items).Errors)
+	}
+
+	// the workers stop at the first errors any of them found
+	res := v.Validate(items, WithFailFast())
+	assert.Len(t, res.Errors, 1)
+	assert.Subset(t, expected, res.Errors)
+
+	res = v.Validate(items, WithMaxErrors(7))
+	assert.Len(t, res.Errors, 7)
+	assert.Subset(t, expected, res.Errors)
+}
+
+func TestValidateParallelMaxErrors(t *testing.T) {
+	var matches int64
+	engine := RegexpEngineFunc(func(pattern string) (Regexp, error) {
+		re := regexp.MustCompile(pattern)
+		return matchFunc(func(s string) bool {
+			atomic.AddInt64(&matches, 1)
+			return re.MatchString(s)
+		}), nil
+	})
+	s := Schema{
+		"items": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"a": map[string]interface{}{"pattern": "^a"},
+				"b": map[string]interface{}{"pattern": "^b"},
+			},
+		},
+	}
+	items := make([]interface{}, 10000)
+	for i := range items {
+		items[i] = map[string]interface{}{"a": "x", "b": "x"}
+	}
+
+	// the workers and the children of an item share the count of errors
+	res := NewValidator(s, WithRegexpEngine(engine), WithParallel(4, 10)).Validate(items, WithMaxErrors(3))
+	assert.Len(t, res.Errors, 3)
+	assert.True(t, atomic.LoadInt64(&matches) < 100, "%d patterns matched", matches)
+
+	atomic.StoreInt64(&matches, 0)
+	res = NewValidator(s, WithRegexpEngine(engine)).Validate(items, WithFailFast())
+	assert.Len(t, res.Errors, 1)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&matches))
+}
+
+// matchFunc adapts a function to a Regexp.
+type matchFunc func(s string) bool
+
+func (f matchFunc) MatchString(s string) bool {
+	return f(s)
+}
+
+func TestValidateParallelCanceled(t *testing.T) {
+	s := Schema{
+		"items": map[string]interface{}{
+			"type": "integer",
+		},
+	}
+	items := make([]interface{}, 100)
+	for i := range items {
+		items[i] = json.Number("1")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res := NewValidator(s, WithParallel(4, 10), WithContext(ctx)).Validate(items)
+	assert.Equal(t, []SchemaError{newError(ValidationCanceledError, "$")}, res.Errors)
+}
+
+type recordEvents struct {
+	events []string
+}
+
+func (r *recordEvents) Emit(name string, path string, keyword string, code ErrorCode) {
+	r.events = append(r.events, name+" "+path+" "+keyword)
+}
+
+func TestValidateParallelEvents(t *testing.T) {
+	s := Schema{
+		"items": map[string]interface{}{
+			"type": "integer",
+		},
+	}
+	items := make([]interface{}, 100)
+	for i := range items {
+		items[i] = json.Number("1")
+	}
+	items[42] = "a"
+
+	expected := &recordEvents{}
+	NewValidator(s).Validate(items, WithEventEmitter(expected))
+
+	actual := &recordEvents{}
+	NewValidator(s, WithParallel(4, 10)).Validate(items, WithEventEmitter(actual))
+	assert.Equal(t, expected.events, actual.events)
+}
@@ -0,0 +1,375 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Loader loads the schema documents referenced by $ref from other documents.
+type Loader interface {
+	Load(uri string) (Schema, error)
+}
+
+// LoaderFunc adapts a function to a Loader.
+type LoaderFunc func(uri string) (Schema, error)
+
+func (f LoaderFunc) Load(uri string) (Schema, error) {
+	return f(uri)
+}
+
+// DefaultLoader loads file URIs and paths from the file system, and http and
+// https URIs with net/http. The documents are read as YAML if their path ends
+// with .yaml or .yml, or if their content type is YAML, and as JSON otherwise.
+// A request times out after 30 seconds, and a document larger than 10 MiB is
+// rejected.
+var DefaultLoader Loader = LoaderFunc(loadURI)
+
+// httpClient loads the http and https URIs of the DefaultLoader.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxLoadSize is the size in bytes of the largest document the DefaultLoader
+// reads.
+var maxLoadSize int64 = 10 << 20
+
+func loadURI(uri string) (Schema, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader
+	isYAML := strings.HasSuffix(u.Path, ".yaml") || strings.HasSuffix(u.Path, ".yml")
+	switch u.Scheme {
+	case "", "file":
+		f, err := os.Open(u.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	case "http", "https":
+		resp, err := httpClient.Get(uri)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Load Error: GET %s returned %s", uri, resp.Status)
+		}
+		r = resp.Body
+		isYAML = isYAML || strings.Contains(resp.Header.Get("Content-Type"), "yaml")
+	default:
+		return nil, fmt.Errorf("Load Error: unsupported scheme %s", u.Scheme)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxLoadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxLoadSize {
+		return nil, fmt.Errorf("Load Error: %s is larger than %d bytes", uri, maxLoadSize)
+	}
+
+	if isYAML {
+		v, _, err := decodeYAML(data, false)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Load Error: %s is not an object", uri)
+		}
+		return Schema(s), nil
+	}
+
+	var s map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&s); err != nil {
+		return nil, err
+	}
+	return Schema(s), nil
+}
+
+// resource is a schema found by a $ref, with its base URI, and the URI of the
+// document it belongs to.
+type resource struct {
+	schema Schema
+	base   string
+	doc    string
+}
+
+// registry resolves $refs to the schemas of the validated document, the ones
+// added with WithSchema, and the ones loaded on demand. It is shared by all the
+// validations of a Validator.
+type registry struct {
+	mu      sync.Mutex
+	loader  Loader
+	remote  bool
+	file    bool
+	docs    map[string]resource
+	anchors map[string]resource
+	// loading holds the documents being loaded by URI.
+	loading map[string]*pendingLoad
+}
+
+// pendingLoad is a document being loaded, done is closed once it is indexed or
+// err is set.
+type pendingLoad struct {
+	done chan struct{}
+	err  error
+}
+
+func newRegistry(root Schema, o *options) *registry {
+	r := &registry{
+		loader:  o.loader,
+		remote:  o.remoteRefs,
+		file:    o.fileRefs,
+		docs:    make(map[string]resource),
+		anchors: make(map[string]resource),
+		loading: make(map[string]*pendingLoad),
+	}
+	if r.loader == nil {
+		r.loader = DefaultLoader
+	}
+
+	for uri, s := range o.schemas {
+		r.add(uri, s)
+	}
+	r.add("", root)
+	return r
+}
+
+// add indexes s as the document at uri, and its subschemas declaring an $id or
+// an $anchor.
+func (r *registry) add(uri string, s Schema) {
+	r.docs[uri] = resource{schema: s, base: schemaBase(uri, s), doc: uri}
+
+	walkScoped(s, "", uri, func(pointer string, base string, sub Schema) error {
+		if id, ok := sub.ID(); ok {
+			if i := strings.Index(id, "#"); i >= 0 && i < len(id)-1 {
+				r.anchors[base+id[i:]] = resource{schema: sub, base: base, doc: uri}
+			} else {
+				r.docs[base] = resource{schema: sub, base: base, doc: uri}
+			}
+		}
+		if anchor, ok := sub["$anchor"].(string); ok {
+			r.anchors[base+"#"+anchor] = resource{schema: sub, base: base, doc: uri}
+		}
+		return nil
+	})
+}
+
+// resolve returns the schema ref points to, ref being relative to base.
+func (r *registry) resolve(base string, ref string) (resource, error) {
+	uri := resolveURI(base, ref)
+	u, err := url.Parse(uri)
+	if err != nil {
+		return resource{}, err
+	}
+	fragment := u.Fragment
+	u.Fragment = ""
+	doc := u.String()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if res, ok := r.anchors[doc+"#"+fragment]; ok {
+		return res, nil
+	}
+
+	res, ok := r.docs[doc]
+	if !ok {
+		if res, err = r.load(doc); err != nil {
+			return resource{}, err
+		}
+		if res, ok := r.anchors[doc+"#"+fragment]; ok {
+			return res, nil
+		}
+	}
+
+	if fragment == "" {
+		return res, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		return resource{}, fmt.Errorf("Resolve Error: no schema with the anchor %s in %s", fragment, doc)
+	}
+	return r.pointer(res, fragment)
+}
+
+// follow returns the schema the $refs of s point to, s being at base, and its
+// base URI. It stops at a $ref that can not be resolved, or after maxDepth
+// $refs in a row, and then returns the schema with that $ref.
+func (r *registry) follow(s Schema, base string, maxDepth int) (Schema, string) {
+	for i := 0; i < maxDepth; i++ {
+		ref, ok := s.Ref()
+		if !ok {
+			break
+		}
+		res, err := r.resolve(base, ref)
+		if err != nil {
+			break
+		}
+		s, base = res.schema, res.base
+	}
+	return s, base
+}
+
+// load loads and indexes the document at uri. It is called with r.mu locked,
+// and unlocks it while the loader runs, so a slow document does not block the
+// $refs to the other ones. The $refs to a document being loaded wait for it.
+func (r *registry) load(uri string) (resource, error) {
+	if !r.remote && isRemote(uri) {
+		return resource{}, fmt.Errorf("Resolve Error: loading the remote schema %s is not allowed", uri)
+	}
+	if !r.file && !isRemote(uri) {
+		return resource{}, fmt.Errorf("Resolve Error: loading the file schema %s is not allowed", uri)
+	}
+
+	if pending, ok := r.loading[uri]; ok {
+		r.mu.Unlock()
+		<-pending.done
+		r.mu.Lock()
+		return r.docs[uri], pending.err
+	}
+
+	pending := &pendingLoad{done: make(chan struct{})}
+	r.loading[uri] = pending
+	r.mu.Unlock()
+	s, err := r.loader.Load(uri)
+	r.mu.Lock()
+
+	delete(r.loading, uri)
+	if err != nil {
+		pending.err = fmt.Errorf("Resolve Error: can not load %s: %s", uri, err)
+	} else {
+		r.add(uri, s)
+	}
+	close(pending.done)
+	return r.docs[uri], pending.err
+}
+
+// pointer returns the schema at the JSON pointer from res.
+func (r *registry) pointer(res resource, pointer string) (resource, error) {
+	var v interface{} = map[string]interface{}(res.schema)
+	base := res.base
+
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = unescapePointer(token)
+
+		switch current := v.(type) {
+		case map[string]interface{}:
+			next, ok := current[token]
+			if !ok {
+				return resource{}, fmt.Errorf("Resolve Error: no %s in %s#%s", token, res.doc, pointer)
+			}
+			v = next
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(current) {
+				return resource{}, fmt.Errorf("Resolve Error: no %s in %s#%s", token, res.doc, pointer)
+			}
+			v = current[i]
+		default:
+			return resource{}, fmt.Errorf("Resolve Error: no %s in %s#%s", token, res.doc, pointer)
+		}
+
+		if s, ok := v.(map[string]interface{}); ok {
+			base = schemaBase(base, Schema(s))
+		}
+	}
+
+	s, ok := v.(map[string]interface{})
+	if !ok {
+		return resource{}, fmt.Errorf("Resolve Error: %s#%s is not a schema", res.doc, pointer)
+	}
+	return resource{schema: Schema(s), base: base, doc: res.doc}, nil
+}
+
+// isRemote reports whether uri must be loaded from the network.
+func isRemote(uri string) bool {
+	u, err := url.Parse(uri)
+	return err != nil || (u.Scheme != "" && u.Scheme != "file")
+}
+
+// resolveURI resolves the URI reference ref against base.
+func resolveURI(base string, ref string) string {
+	if base == "" {
+		return ref
+	}
+
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// schemaBase returns the base URI of s, whose parent's base URI is base.
+func schemaBase(base string, s Schema) string {
+	id, ok := s.ID()
+	if !ok {
+		return base
+	}
+
+	uri := resolveURI(base, id)
+	if i := strings.Index(uri, "#"); i >= 0 {
+		uri = uri[:i]
+	}
+	return uri
+}
+
+// followRef returns a constraint for the schema ref points to, or nil after
+// adding the error telling why ref can not be followed.
+func (b *baseConstraint) followRef(ref string, path string) *baseConstraint {
+	if b.opts == nil || b.opts.refs == nil {
+		b.addError(newError(RefError, path).withParam("ref", ref).withParam("error", "no schema to resolve it in"))
+		return nil
+	}
+
+	if max := b.opts.maxRefDepth; b.refDepth >= max {
+		b.addError(newError(MaxRefDepthError, path).withParam("ref", ref).withParam("limit", max))
+		return nil
+	}
+
+	res, err := b.opts.refs.resolve(b.base, ref)
+	if err != nil {
+		b.addError(newError(RefError, path).withParam("ref", ref).withParam("error", err.Error()))
+		return nil
+	}
+
+	c := NewBaseConstraint(res.schema)
+	c.opts = b.opts
+	c.base = res.base
+	c.refDepth = b.refDepth + 1
+	c.location = b.location + "/$ref"
+	return c
+}
+
+// validateRef validates v against the schema ref points to. As in drafts 4 to 7,
+// the other keywords of a schema declaring $ref are ignored.
+func (b *baseConstraint) validateRef(ref string, v interface{}, path string) {
+	c := b.followRef(ref, path)
+	if c == nil {
+		return
+	}
+
+	c.Validate(v, path)
+	b.addErrors(c.Errors())
+	if c.replaced {
+		b.replaced, b.replacement = true, c.replacement
+	}
+}
@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRef(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"$id": "http://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"name": {"$ref": "#/definitions/name"},
+			"tags": {"type": "array", "items": {"$ref": "#tag"}},
+			"owner": {"$ref": "person.json"},
+			"child": {"$ref": "#"}
+		},
+		"definitions": {
+			"name": {"type": "string", "maxLength": 3},
+			"tag": {"$id": "#tag", "type": "string"}
+		}
+	}`)
+	assert.NoError(t, err)
+
+	person, err := deserializeSchema(`{"required": ["name"]}`)
+	assert.NoError(t, err)
+
+	v, err := deserializeValue(`
+	{
+		"name": "abcd",
+		"tags": ["a", 1],
+		"owner": {},
+		"child": {"name": 1}
+	}`)
+	assert.NoError(t, err)
+
+	res := NewValidator(s, WithSchema("http://example.com/person.json", person)).Validate(v)
+	assert.Equal(t, []SchemaError{
+		newError(TypeNotMatchError, "$.child.name").withParam("expected", JsonString),
+		newError(StringMaxLengthError, "$.name").withParam("limit", 3),
+		newError(ObjectRequiredPropertiesError, "$.owner").withParam("property", "name"),
+		newError(TypeNotMatchError, "$.tags[1]").withParam("expected", JsonString),
+	}, res.Errors)
+
+	// the stream validation follows the $refs too
+	streamed, err := NewValidator(s, WithSchema("http://example.com/person.json", person)).ValidateReader(strings.NewReader(`
+	{
+		"name": "abcd",
+		"tags": ["a", 1],
+		"owner": {},
+		"child": {"name": 1}
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, errorStrings(res.Errors), errorStrings(streamed.Errors))
+}
+
+func TestRefLoader(t *testing.T) {
+	s := Schema{"$ref": "http://example.com/string.json"}
+
+	var loaded []string
+	loader := LoaderFunc(func(uri string) (Schema, error) {
+		loaded = append(loaded, uri)
+		if uri == "http://example.com/string.json" {
+			return Schema{"type": "string"}, nil
+		}
+		return nil, errors.New("not found")
+	})
+
+	validator := NewValidator(s, WithLoader(loader), WithRemoteRefs())
+	assert.True(t, validator.Validate("a").Valid())
+	assert.False(t, validator.Validate(true).Valid())
+	// the document is loaded once
+	assert.Equal(t, []string{"http://example.com/string.json"}, loaded)
+
+	res := NewValidator(s, WithLoader(loader)).Validate("a")
+	assert.Len(t, res.Errors, 1)
+	assert.Equal(t, RefError, res.Errors[0].Code())
+	assert.Equal(t, "$: can not be validated, the $ref http://example.com/string.json can not be resolved: "+
+		"Resolve Error: loading the remote schema http://example.com/string.json is not allowed", res.Messages()[0])
+
+	// a document added with WithSchema is not loaded
+	res = NewValidator(s, WithLoader(loader),
+		WithSchema("http://example.com/string.json", Schema{"type": "string"})).Validate("a")
+	assert.True(t, res.Valid())
+
+	res = NewValidator(Schema{"$ref": "#/definitions/missing"}).Validate("a")
+	assert.Len(t, res.Errors, 1)
+	assert.Equal(t, RefError, res.Errors[0].Code())
+
+	for _, ref := range []string{"string.json", "/etc/string.json", "file:///etc/string.json"} {
+		res = NewValidator(Schema{"$ref": ref}, WithLoader(loader), WithRemoteRefs()).Validate("a")
+		assert.Len(t, res.Errors, 1)
+		assert.Contains(t, res.Messages()[0], "Resolve Error: loading the file schema "+ref+" is not allowed")
+	}
+	assert.Len(t, loaded, 1)
+}
+
+func TestRefLoaderConcurrent(t *testing.T) {
+	started, slow := make(chan struct{}), make(chan struct{})
+	var mu sync.Mutex
+	loaded := make(map[string]int)
+	loader := LoaderFunc(func(uri string) (Schema, error) {
+		mu.Lock()
+		loaded[uri]++
+		mu.Unlock()
+		if uri == "http://example.com/slow.json" {
+			close(started)
+			<-slow
+		}
+		return Schema{"type": "string"}, nil
+	})
+	refs := newRegistry(Schema{}, newOptions(WithLoader(loader), WithRemoteRefs()))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := refs.resolve("", "http://example.com/slow.json")
+			assert.NoError(t, err)
+			assert.Equal(t, Schema{"type": "string"}, res.schema)
+		}()
+	}
+
+	// the other documents are resolved while the slow one is loaded
+	<-started
+	for i := 0; i < 5; i++ {
+		res, err := refs.resolve("", "http://example.com/fast.json")
+		assert.NoError(t, err)
+		assert.Equal(t, Schema{"type": "string"}, res.schema)
+	}
+	close(slow)
+	wg.Wait()
+
+	// each document is loaded once
+	assert.Equal(t, map[string]int{"http://example.com/slow.json": 1, "http://example.com/fast.json": 1}, loaded)
+}
+
+func TestDefaultLoaderLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"type": "string", "description": "%s"}`, strings.Repeat("a", 100))
+	}))
+	defer server.Close()
+
+	s, err := DefaultLoader.Load(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "string", s["type"])
+
+	defer func(size int64) { maxLoadSize = size }(maxLoadSize)
+	maxLoadSize = 64
+
+	_, err = DefaultLoader.Load(server.URL)
+	assert.EqualError(t, err, "Load Error: "+server.URL+" is larger than 64 bytes")
+}
+
+func TestRefCircular(t *testing.T) {
+	res := NewValidator(Schema{"$ref": "#"}).Validate("a")
+	assert.Equal(t, []SchemaError{
+		newError(MaxRefDepthError, "$").withParam("ref", "#").withParam("limit", DefaultMaxRefDepth),
+	}, res.Errors)
+
+	s := Schema{
+		"definitions": map[string]interface{}{
+			"a": map[string]interface{}{"$ref": "#/definitions/b"},
+			"b": map[string]interface{}{"allOf": []interface{}{map[string]interface{}{"$ref": "#/definitions/a"}}},
+		},
+		"$ref": "#/definitions/a",
+	}
+
+	res = NewValidator(s, WithMaxRefDepth(10)).Validate("a")
+	assert.Equal(t, []SchemaError{newError(AllOfError, "$")}, res.Errors)
+
+	// the depth is counted from the last value entered, so recursive schemas can
+	// validate deeper instances
+	tree := Schema{
+		"properties": map[string]interface{}{
+			"child": map[string]interface{}{"$ref": "#"},
+		},
+		"required": []interface{}{"child"},
+	}
+	v, err := deserializeValue(`{"child": {"child": {"child": {"child": {}}}}}`)
+	assert.NoError(t, err)
+
+	res = NewValidator(tree, WithMaxRefDepth(1)).Validate(v)
+	assert.Equal(t, []SchemaError{
+		newError(ObjectRequiredPropertiesError, "$.child.child.child.child").withParam("property", "child"),
+	}, res.Errors)
+}
@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Regexp is a compiled pattern of the pattern or patternProperties keywords.
+type Regexp interface {
+	MatchString(s string) bool
+}
+
+// RegexpEngine compiles the patterns of the pattern and patternProperties
+// keywords. JSON Schema patterns follow ECMA-262, an engine implementing it can
+// replace the DefaultRegexpEngine, which uses the RE2 syntax of package regexp.
+type RegexpEngine interface {
+	Compile(pattern string) (Regexp, error)
+}
+
+// RegexpEngineFunc adapts a function to a RegexpEngine.
+type RegexpEngineFunc func(pattern string) (Regexp, error)
+
+func (f RegexpEngineFunc) Compile(pattern string) (Regexp, error) {
+	return f(pattern)
+}
+
+// DefaultRegexpEngine compiles the patterns with package regexp.
+var DefaultRegexpEngine RegexpEngine = RegexpEngineFunc(func(pattern string) (Regexp, error) {
+	return regexp.Compile(pattern)
+})
+
+// defaultRegexps caches the patterns compiled with the DefaultRegexpEngine, for
+// the constraints that are not created by a Validator. As they can come from any
+// number of schemas, only the defaultRegexpsSize most recently used patterns are
+// kept.
+var defaultRegexps = newRegexpCache(nil, 0, defaultRegexpsSize)
+
+// defaultRegexpsSize is the number of patterns kept by defaultRegexps.
+const defaultRegexpsSize = 1000
+
+// regexpCache compiles each pattern once with its engine. It is shared by all
+// the validations of a Validator, and keeps all the patterns of its schemas.
+type regexpCache struct {
+	engine RegexpEngine
+	// maxLength is the limit of the maximum pattern length option, or 0.
+	maxLength int
+	// size is the number of patterns kept, the least recently used one being
+	// evicted beyond it, or 0 to keep them all.
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order holds the *regexpEntry of the patterns, the most recently used first.
+	order *list.List
+}
+
+// regexpEntry is a pattern of a regexpCache, compiled or not.
+type regexpEntry struct {
+	pattern string
+	re      Regexp
+	err     error
+}
+
+func newRegexpCache(engine RegexpEngine, maxLength int, size int) *regexpCache {
+	if engine == nil {
+		engine = DefaultRegexpEngine
+	}
+	return &regexpCache{
+		engine:    engine,
+		maxLength: maxLength,
+		size:      size,
+		entries:   make(map[string]*list.Element),
+		order:     list.New(),
+	}
+}
+
+// compile returns the compiled pattern, or the error of the engine. A pattern
+// longer than the maximum length is not given to the engine.
+func (c *regexpCache) compile(pattern string) (Regexp, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[pattern]; ok {
+		c.order.MoveToFront(elem)
+		entry := elem.Value.(*regexpEntry)
+		c.mu.Unlock()
+		return entry.re, entry.err
+	}
+	c.mu.Unlock()
+
+	entry := &regexpEntry{pattern: pattern}
+	if c.maxLength > 0 && len(pattern) > c.maxLength {
+		entry.err = fmt.Errorf("it is longer than %d bytes", c.maxLength)
+	} else {
+		entry.re, entry.err = c.engine.Compile(pattern)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[pattern]; ok {
+		// compiled concurrently
+		c.order.MoveToFront(elem)
+		return entry.re, entry.err
+	}
+	c.entries[pattern] = c.order.PushFront(entry)
+	if c.size > 0 && c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*regexpEntry).pattern)
+	}
+	return entry.re, entry.err
+}
+
+// match reports whether s matches pattern. If pattern can not be compiled, ok is
+// false and an InvalidPatternError is added at path.
+func (b *baseConstraint) match(pattern string, s string, path string) (matched bool, ok bool) {
+	cache := defaultRegexps
+	if b.opts != nil && b.opts.regexps != nil {
+		cache = b.opts.regexps
+	}
+
+	re, err := cache.compile(pattern)
+	if err != nil {
+		b.addError(newError(InvalidPatternError, path).withParam("pattern", pattern).withParam("error", err.Error()))
+		return false, false
+	}
+	return re.MatchString(s), true
+}
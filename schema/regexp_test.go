@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexpEngine(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"properties": {
+			"a": {"pattern": "(?=x)x"}
+		},
+		"patternProperties": {
+			"b(?!c)": {"type": "string"}
+		}
+	}`)
+	assert.NoError(t, err)
+
+	v, err := deserializeValue(`{"a": "x", "bd": 1}`)
+	assert.NoError(t, err)
+
+	// RE2 rejects lookarounds, they are reported instead of panicking
+	res := NewValidator(s).Validate(v)
+	assert.Equal(t, []SchemaError{
+		newError(InvalidPatternError, "$.a").withParam("pattern", "b(?!c)").
+			withParam("error", "error parsing regexp: invalid or unsupported Perl syntax: `(?!`"),
+		newError(InvalidPatternError, "$.a").withParam("pattern", "(?=x)x").
+			withParam("error", "error parsing regexp: invalid or unsupported Perl syntax: `(?=`"),
+		newError(InvalidPatternError, "$.bd").withParam("pattern", "b(?!c)").
+			withParam("error", "error parsing regexp: invalid or unsupported Perl syntax: `(?!`"),
+	}, res.Errors)
+
+	_, err = Compile(s)
+	assert.EqualError(t, err, "Compile Error: invalid pattern at #: error parsing regexp: invalid or unsupported Perl syntax: `(?!`")
+
+	// an engine that knows the lookarounds of the schema
+	translations := map[string]string{
+		"(?=x)x": "^x",
+		"b(?!c)": "^b([^c]|$)",
+	}
+	compiled := 0
+	engine := RegexpEngineFunc(func(pattern string) (Regexp, error) {
+		compiled++
+		if translation, ok := translations[pattern]; ok {
+			return regexp.Compile(translation)
+		}
+		return nil, errors.New("unsupported pattern")
+	})
+
+	validator, err := Compile(s, WithRegexpEngine(engine))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, compiled)
+
+	res = validator.Validate(v)
+	assert.Equal(t, []SchemaError{
+		newError(TypeNotMatchError, "$.bd").withParam("expected", JsonString),
+	}, res.Errors)
+	// the patterns were compiled by Compile
+	assert.Equal(t, 2, compiled)
+
+	res = validator.Validate(map[string]interface{}{"a": "y"})
+	assert.Equal(t, []SchemaError{
+		newError(StringPatternError, "$.a").withParam("pattern", "(?=x)x"),
+	}, res.Errors)
+}
+
+func TestRegexpEngineStream(t *testing.T) {
+	s := Schema{
+		"patternProperties": map[string]interface{}{
+			"b(?!c)": map[string]interface{}{"type": "string"},
+		},
+		"additionalProperties": false,
+	}
+	engine := RegexpEngineFunc(func(pattern string) (Regexp, error) {
+		return regexp.Compile("^b([^c]|$)")
+	})
+
+	// the streaming validation matches with the same engine as Validate, and
+	// reports the same invalid patterns
+	for _, validator := range []*Validator{NewValidator(s, WithRegexpEngine(engine)), NewValidator(s)} {
+		for _, doc := range []string{`{"bd": 1}`, `{"bc": "x", "bd": 1}`} {
+			v, err := deserializeValue(doc)
+			assert.NoError(t, err)
+			expected := validator.Validate(v).Errors
+			assert.NotEmpty(t, expected)
+
+			res, err := validator.ValidateReader(strings.NewReader(doc))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, res.Errors, doc)
+		}
+	}
+}
+
+func TestRegexpCacheSize(t *testing.T) {
+	var compiled []string
+	engine := RegexpEngineFunc(func(pattern string) (Regexp, error) {
+		compiled = append(compiled, pattern)
+		return regexp.Compile(pattern)
+	})
+	cache := newRegexpCache(engine, 0, 2)
+
+	for _, pattern := range []string{"a", "b", "a", "c", "b", "a"} {
+		re, err := cache.compile(pattern)
+		assert.NoError(t, err)
+		assert.True(t, re.MatchString(pattern))
+	}
+	// c evicts b, the least recently used, which evicts a when compiled again
+	assert.Equal(t, []string{"a", "b", "c", "b", "a"}, compiled)
+	assert.Equal(t, 2, cache.order.Len())
+}
@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Sanitize returns a copy of v without the properties its schema does not
+// declare, and the paths of the removed properties. A property of an object is
+// removed when one of the schemas applying to it sets additionalProperties to
+// false and does not declare the property in its own properties or
+// patternProperties, which is when the validation reports it as undefined. The
+// schemas applying to a value are its schema and the subschemas of its allOf,
+// which must all accept a property, and the branches of its anyOf and oneOf, one
+// of which must accept it. As the instance is not validated, a branch accepts
+// the properties it declares whether the other properties match it or not.
+func (validator *Validator) Sanitize(v interface{}) (sanitized interface{}, removed []string) {
+	return validator.sanitize(v, validator.options(nil))
+}
+
+func (validator *Validator) sanitize(v interface{}, o *options) (interface{}, []string) {
+	z := &sanitizer{opts: o}
+	n := &sanitizeNode{}
+	z.applicable(n, validator.schema, schemaBase("", validator.schema), 0)
+	return z.sanitize(v, n, rootPath), z.removed
+}
+
+type sanitizer struct {
+	opts    *options
+	removed []string
+}
+
+// scopedSchema is a schema with its base URI.
+type scopedSchema struct {
+	schema Schema
+	base   string
+}
+
+// sanitizeNode holds the schemas applying to a value: all the schemas of all,
+// and one of the alternatives of each group of any, the branches of an anyOf or
+// a oneOf.
+type sanitizeNode struct {
+	all []scopedSchema
+	any [][]*sanitizeNode
+}
+
+// empty reports whether no schema applies to the value.
+func (n *sanitizeNode) empty() bool {
+	return len(n.all) == 0 && len(n.any) == 0
+}
+
+// applicable adds s and the subschemas of its allOf to n, and the branches of
+// its anyOf and oneOf as groups of alternatives, following the $refs.
+func (z *sanitizer) applicable(n *sanitizeNode, s Schema, base string, refDepth int) {
+	if ref, ok := s.Ref(); ok {
+		if z.opts.refs == nil || refDepth >= z.opts.maxRefDepth {
+			return
+		}
+		res, err := z.opts.refs.resolve(base, ref)
+		if err != nil {
+			return
+		}
+		z.applicable(n, res.schema, res.base, refDepth+1)
+		return
+	}
+
+	n.all = append(n.all, scopedSchema{schema: s, base: base})
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		subs, _ := s[keyword].([]interface{})
+		var group []*sanitizeNode
+		for _, sub := range subs {
+			one, ok := sub.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if keyword == "allOf" {
+				z.applicable(n, Schema(one), schemaBase(base, Schema(one)), refDepth)
+				continue
+			}
+			alt := &sanitizeNode{}
+			z.applicable(alt, Schema(one), schemaBase(base, Schema(one)), refDepth)
+			group = append(group, alt)
+		}
+		if len(group) > 0 {
+			n.any = append(n.any, group)
+		}
+	}
+}
+
+// sanitize returns the sanitized copy of v, to which the schemas of n apply.
+func (z *sanitizer) sanitize(v interface{}, n *sanitizeNode, path string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return z.sanitizeObject(v, n, path)
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			sub := z.child(n, func(s scopedSchema) ([]Schema, bool) {
+				if one, _, _ := itemSchema(s.schema, i); one != nil {
+					return []Schema{one}, true
+				}
+				return nil, true
+			})
+			arr[i] = z.sanitize(item, sub, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return arr
+	default:
+		return v
+	}
+}
+
+func (z *sanitizer) sanitizeObject(obj map[string]interface{}, n *sanitizeNode, path string) map[string]interface{} {
+	// sorted, so that the removed properties are reported in a stable order
+	props := make([]string, 0, len(obj))
+	for prop := range obj {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	sanitized := make(map[string]interface{}, len(obj))
+	for _, prop := range props {
+		subPath := fmt.Sprintf("%s.%s", path, prop)
+
+		// as in ObjectConstraint, each schema only allows the properties it
+		// declares itself when its additionalProperties is false
+		sub := z.child(n, func(s scopedSchema) ([]Schema, bool) {
+			own, _, ok := s.schema.propertySchemas(prop, func(pattern string) bool {
+				re, err := z.opts.regexps.compile(pattern)
+				return err == nil && re.MatchString(prop)
+			})
+			return own, ok
+		})
+
+		if sub == nil {
+			z.removed = append(z.removed, subPath)
+			continue
+		}
+		sanitized[prop] = z.sanitize(obj[prop], sub, subPath)
+	}
+	return sanitized
+}
+
+// child returns the node of the schemas applying to a property or an item of the
+// values n applies to, given by schemas for each schema of n, or nil if the
+// property is not allowed by all the schemas of n or by any alternative of one
+// of its groups.
+func (z *sanitizer) child(n *sanitizeNode, schemas func(s scopedSchema) ([]Schema, bool)) *sanitizeNode {
+	c := &sanitizeNode{}
+	for _, s := range n.all {
+		own, ok := schemas(s)
+		if !ok {
+			return nil
+		}
+		for _, one := range own {
+			z.applicable(c, one, schemaBase(s.base, one), 0)
+		}
+	}
+
+	for _, group := range n.any {
+		var alts []*sanitizeNode
+		unconstrained := false
+		for _, alt := range group {
+			sub := z.child(alt, schemas)
+			if sub == nil {
+				continue
+			}
+			if sub.empty() {
+				unconstrained = true
+			}
+			alts = append(alts, sub)
+		}
+		if len(alts) == 0 {
+			return nil
+		}
+		// an alternative without schemas accepts everything
+		if !unconstrained {
+			c.any = append(c.any, alts)
+		}
+	}
+	return c
+}
@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string"},
+			"address": {"$ref": "#/definitions/address"},
+			"tags": {"type": "array", "items": {"additionalProperties": false, "properties": {"key": {}}}},
+			"meta": {"type": "object"}
+		},
+		"allOf": [
+			{"properties": {"email": {"type": "string"}}},
+			{"patternProperties": {"^x-": {}}}
+		],
+		"definitions": {
+			"address": {
+				"additionalProperties": false,
+				"properties": {"city": {"type": "string"}}
+			}
+		}
+	}`)
+	assert.NoError(t, err)
+
+	v, err := deserializeValue(`
+	{
+		"name": "a",
+		"email": "a@example.com",
+		"x-trace": "1",
+		"password": "secret",
+		"address": {"city": "b", "zip": "1"},
+		"tags": [{"key": "c", "value": "d"}],
+		"meta": {"anything": true}
+	}`)
+	assert.NoError(t, err)
+
+	expected, err := deserializeValue(`
+	{
+		"name": "a",
+		"address": {"city": "b"},
+		"tags": [{"key": "c"}],
+		"meta": {"anything": true}
+	}`)
+	assert.NoError(t, err)
+
+	validator := NewValidator(s)
+	sanitized, removed := validator.Sanitize(v)
+	assert.Equal(t, expected, sanitized)
+	// additionalProperties ignores the properties declared in allOf, so they are
+	// removed as well
+	assert.Equal(t, []string{"$.address.zip", "$.email", "$.password", "$.tags[0].value", "$.x-trace"}, removed)
+
+	// the instance is not modified
+	assert.Contains(t, v.(map[string]interface{}), "password")
+
+	// the sanitized copy is valid
+	assert.True(t, validator.Validate(sanitized).Valid())
+
+	res := validator.Validate(v, WithRemoveAdditional())
+	assert.True(t, res.Valid())
+	assert.Equal(t, expected, res.Value)
+	assert.Equal(t, removed, res.Removed)
+}
+
+func TestSanitizeAlternatives(t *testing.T) {
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		s, err := deserializeSchema(`
+		{
+			"` + keyword + `": [
+				{"additionalProperties": false, "properties": {"a": {}, "n": {"additionalProperties": false, "properties": {"x": {}}}}},
+				{"additionalProperties": false, "properties": {"c": {}, "n": {"additionalProperties": false, "properties": {"y": {}}}}}
+			]
+		}`)
+		assert.NoError(t, err)
+
+		// a property is kept when one branch accepts it
+		v, err := deserializeValue(`{"a": 1, "c": 2, "b": 3, "n": {"x": 1, "y": 2, "z": 3}}`)
+		assert.NoError(t, err)
+		expected, err := deserializeValue(`{"a": 1, "c": 2, "n": {"x": 1, "y": 2}}`)
+		assert.NoError(t, err)
+
+		sanitized, removed := NewValidator(s).Sanitize(v)
+		assert.Equal(t, expected, sanitized, keyword)
+		assert.Equal(t, []string{"$.b", "$.n.z"}, removed, keyword)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -92,7 +93,12 @@ func (s Schema) getIntValue(key string) (value int, exist bool) {
 	exist = false
 
 	if v, ok := s[key]; ok {
-		i64, _ := v.(json.Number).Int64()
+		i64, err := v.(json.Number).Int64()
+		if err != nil {
+			// an integer written with a fraction, such as 1.0
+			f, _ := v.(json.Number).Float64()
+			i64 = int64(f)
+		}
 		value = int(i64)
 		exist = true
 	}
@@ -112,6 +118,23 @@ func (s Schema) getStringValue(key string) (value string, exist bool) {
 	return
 }
 
+// core keywords
+
+// Ref returns the URI reference of the $ref keyword.
+func (s Schema) Ref() (ref string, exist bool) {
+	ref, exist = s["$ref"].(string)
+	return
+}
+
+// ID returns the $id of the schema, or its id as in draft 4.
+func (s Schema) ID() (id string, exist bool) {
+	if id, exist = s["$id"].(string); exist {
+		return
+	}
+	id, exist = s["id"].(string)
+	return
+}
+
 // validation keywords for any instance
 
 func (s Schema) Type() (jsonType JsonType, jsonTypes []JsonType, exist bool) {
@@ -202,6 +225,30 @@ func (s Schema) Not() (not Schema, exist bool) {
 	return
 }
 
+// ErrorMessage returns the custom messages of the schema. The keyword is either
+// a single message for every error of the schema, or an object mapping keywords
+// to messages.
+func (s Schema) ErrorMessage() (message string, keywordMessages map[string]string, exist bool) {
+	v, exist := s["errorMessage"]
+	if !exist {
+		return
+	}
+
+	switch v.(type) {
+	case string:
+		message = v.(string)
+	case map[string]interface{}:
+		keywordMessages = make(map[string]string)
+		for keyword, msg := range v.(map[string]interface{}) {
+			if str, ok := msg.(string); ok {
+				keywordMessages[keyword] = str
+			}
+		}
+	}
+
+	return
+}
+
 // validation keywords for numeric
 
 func (s Schema) MultipleOf() (divided float64, exist bool) {
@@ -363,13 +410,15 @@ func (s Schema) AdditionalProperties() (additionSchema Schema, boolValue bool, e
 	}
 }
 
-func (s Schema) PatternProperties() (patternSchema PatternProperties, exist bool) {
+// PatternProperties returns a map where the key is a regular expression and the
+// value is a valid json schema for the properties matching it.
+func (s Schema) PatternProperties() (patternSchema map[string]Schema, exist bool) {
 	v, exist := s["patternProperties"]
 	if !exist {
 		return
 	}
 
-	patternSchema = make(PatternProperties)
+	patternSchema = make(map[string]Schema)
 	for key, val := range v.(map[string]interface{}) {
 		patternSchema[key] = Schema(val.(map[string]interface{}))
 	}
@@ -377,8 +426,54 @@ func (s Schema) PatternProperties() (patternSchema PatternProperties, exist bool
 	return
 }
 
-type PatternProperties map[string]Schema
+// PropertySchemas returns the schemas that apply to the property prop: its
+// schema in "properties", the schemas of the "patternProperties" it matches, or
+// else the schema of "additionalProperties". allowed is false if prop is not
+// allowed at all by "additionalProperties": false. The patterns are compiled
+// with the DefaultRegexpEngine, and an invalid one matches nothing.
+func (s Schema) PropertySchemas(prop string) (schemas []Schema, allowed bool) {
+	schemas, _, allowed = s.propertySchemas(prop, func(pattern string) bool {
+		re, err := defaultRegexps.compile(pattern)
+		return err == nil && re.MatchString(prop)
+	})
+	return
+}
+
+// propertySchemas is PropertySchemas, matching prop against the patterns with
+// match. tokens holds the reference tokens of each schema from s.
+func (s Schema) propertySchemas(prop string, match func(pattern string) bool) (schemas []Schema, tokens [][]string, allowed bool) {
+	if props, ok := s.Properties(); ok {
+		if one, ok := props[prop]; ok {
+			schemas = append(schemas, one)
+			tokens = append(tokens, []string{"properties", prop})
+		}
+	}
 
-func (p PatternProperties) match(prop string, val interface{}) bool {
+	if patterns, ok := s.PatternProperties(); ok {
+		keys := make([]string, 0, len(patterns))
+		for pattern := range patterns {
+			keys = append(keys, pattern)
+		}
+		sort.Strings(keys)
 
-}
\ No newline at end of file
+		for _, pattern := range keys {
+			if match(pattern) {
+				schemas = append(schemas, patterns[pattern])
+				tokens = append(tokens, []string{"patternProperties", pattern})
+			}
+		}
+	}
+
+	if len(schemas) > 0 {
+		return schemas, tokens, true
+	}
+
+	additionSchema, allowAddition, exist := s.AdditionalProperties()
+	if !exist {
+		return nil, nil, true
+	}
+	if additionSchema != nil {
+		return []Schema{additionSchema}, [][]string{{"additionalProperties"}}, true
+	}
+	return nil, nil, allowAddition
+}
@@ -1,10 +1,11 @@
 package schema
 
 import (
-	"code.yieldr.com/px/util/test/assert"
 	"encoding/json"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func deserializeSchema(str string) (Schema, error) {
@@ -141,8 +142,8 @@ func TestObjectSchema(t *testing.T) {
 			}
 			`,
 			expectedAdditionSchema: Schema(nil),
-			expectedBool: false,
-			expectedExist: true,
+			expectedBool:           false,
+			expectedExist:          true,
 		},
 		{
 			input: `
@@ -155,7 +156,7 @@ func TestObjectSchema(t *testing.T) {
 			expectedAdditionSchema: Schema{
 				"type": "integer",
 			},
-			expectedBool: false,
+			expectedBool:  false,
 			expectedExist: true,
 		},
 	}
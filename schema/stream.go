@@ -0,0 +1,472 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// errStopped unwinds a stream validation that must not read further, because of
+// the fail-fast, maximum errors or abort options, or a done context.
+var errStopped = errors.New("validation stopped")
+
+// errTooDeep unwinds a stream validation whose document is nested deeper than
+// the maximum depth option.
+var errTooDeep = errors.New("document too deep")
+
+// wholeValueKeywords are the keywords that need the whole value to be evaluated,
+// so a value validated against a schema declaring one of them is materialized.
+var wholeValueKeywords = []string{"enum", "allOf", "anyOf", "oneOf", "not"}
+
+// ValidateReader validates the JSON document read from r, see ValidateStream.
+// Anything but white space after the document makes it invalid JSON, unless the
+// validation stopped before its end.
+func (validator *Validator) ValidateReader(r io.Reader, opts ...Option) (*Result, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	res, stopped, err := validator.validateStream(dec, opts)
+	if err != nil || stopped {
+		return res, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("unexpected data after the document")
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// ValidateStream validates the next JSON document of dec token by token. Arrays
+// and objects are only materialized when their schema needs them as a whole,
+// i.e. for enum, allOf, anyOf, oneOf and not, and each item of an array with
+// uniqueItems, whose hash is kept. Otherwise the memory used is proportional to
+// the nesting depth of the document. What follows the document is left in dec,
+// so that the next one can be validated.
+//
+// The returned error is only set if the document is not valid JSON. Result.Value
+// is always nil, and values replaced in an interactive validation are dropped.
+func (validator *Validator) ValidateStream(dec *json.Decoder, opts ...Option) (*Result, error) {
+	res, _, err := validator.validateStream(dec, opts)
+	return res, err
+}
+
+// validateStream validates the next JSON document of dec. stopped is true if the
+// document was not read to its end.
+func (validator *Validator) validateStream(dec *json.Decoder, opts []Option) (res *Result, stopped bool, err error) {
+	o := validator.options(opts)
+
+	c := validator.root(o)
+
+	s := &streamDecoder{dec: dec, maxDepth: o.maxDepth}
+	tok, err := s.token()
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = s.validate(c, tok, rootPath)
+	if err == errTooDeep {
+		return &Result{
+			Errors:    []SchemaError{newError(MaxDepthError, rootPath).withParam("limit", o.maxDepth)},
+			formatter: o.formatter,
+		}, true, nil
+	}
+	if err == io.EOF {
+		// the document started, so it is truncated
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil && err != errStopped {
+		return nil, false, err
+	}
+	c.addContextError(rootPath)
+	c.addLimitError(rootPath)
+
+	return &Result{
+		Errors:    c.Errors(),
+		formatter: o.formatter,
+		locations: o.locations,
+	}, err == errStopped, nil
+}
+
+type streamDecoder struct {
+	dec *json.Decoder
+
+	// depth is the number of arrays and objects the decoder is in, and maxDepth
+	// the limit of the maximum depth option, or 0.
+	depth    int
+	maxDepth int
+}
+
+// token returns the next token, with numbers as json.Number.
+func (s *streamDecoder) token() (json.Token, error) {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if delim, ok := tok.(json.Delim); ok {
+		if delim == '[' || delim == '{' {
+			s.depth++
+		} else {
+			s.depth--
+		}
+		if s.maxDepth > 0 && s.depth > s.maxDepth {
+			return nil, errTooDeep
+		}
+	}
+
+	if f, ok := tok.(float64); ok {
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+	}
+	return tok, nil
+}
+
+// validate validates the value starting with tok against the schema of c.
+func (s *streamDecoder) validate(c *baseConstraint, tok json.Token, path string) error {
+	if ref, ok := c.schema.Ref(); ok {
+		return s.validateRef(c, ref, tok, path)
+	}
+
+	delim, isDelim := tok.(json.Delim)
+	if !isDelim || s.needsWholeValue(c) {
+		v, err := s.value(tok)
+		if err != nil {
+			return err
+		}
+		c.Validate(v, path)
+		return s.stop(c)
+	}
+	if !c.evaluate() {
+		return errStopped
+	}
+
+	c.emit(SchemaEnteredEvent, path, "", "")
+	defer c.emit(SchemaExitedEvent, path, "", "")
+
+	if delim == '[' {
+		if _, ok := c.schema["type"]; ok {
+			c.validateType([]interface{}{}, path)
+			c.evaluated(path, "type")
+		}
+		if err := s.stop(c); err != nil {
+			return err
+		}
+		return s.validateArray(c, path)
+	}
+
+	if _, ok := c.schema["type"]; ok {
+		c.validateType(map[string]interface{}{}, path)
+		c.evaluated(path, "type")
+	}
+	if err := s.stop(c); err != nil {
+		return err
+	}
+	return s.validateObject(c, path)
+}
+
+// validateRef validates the value starting with tok against the schema ref points
+// to, as in baseConstraint.validateRef.
+func (s *streamDecoder) validateRef(c *baseConstraint, ref string, tok json.Token, path string) error {
+	if !c.evaluate() {
+		return errStopped
+	}
+
+	c.emit(SchemaEnteredEvent, path, "", "")
+	defer c.emit(SchemaExitedEvent, path, "", "")
+
+	target := c.followRef(ref, path)
+	if target == nil {
+		if err := s.skip(tok); err != nil {
+			return err
+		}
+		return s.stop(c)
+	}
+
+	err := s.validate(target, tok, path)
+	c.addErrors(target.Errors())
+	c.evaluated(path, "$ref")
+	if err != nil {
+		return err
+	}
+	return s.stop(c)
+}
+
+func (s *streamDecoder) needsWholeValue(c *baseConstraint) bool {
+	for _, keyword := range wholeValueKeywords {
+		if _, ok := c.schema[keyword]; ok {
+			return true
+		}
+	}
+
+	if c.opts != nil && c.opts.errorMessages {
+		_, ok := c.schema["errorMessage"]
+		return ok
+	}
+	return false
+}
+
+// stop returns errStopped if no more errors can be collected by c.
+func (s *streamDecoder) stop(c *baseConstraint) error {
+	if c.done() || c.aborted() || c.canceled() || c.exhausted() {
+		return errStopped
+	}
+	return nil
+}
+
+func (s *streamDecoder) validateArray(c *baseConstraint, path string) error {
+	unique := c.schema.UniqueItems()
+	seen := make(map[[sha256.Size]byte]bool)
+
+	count := 0
+	for ; s.dec.More(); count++ {
+		tok, err := s.token()
+		if err != nil {
+			return err
+		}
+		subPath := fmt.Sprintf("%s[%d]", path, count)
+
+		if c.stopped() {
+			if err := s.skip(tok); err != nil {
+				return err
+			}
+			continue
+		}
+
+		one, tokens, allowed := itemSchema(c.schema, count)
+
+		if !allowed {
+			c.addError(newError(ArrayAdditionalItemError, subPath))
+			if err := s.skip(tok); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if unique {
+			v, err := s.value(tok)
+			if err != nil {
+				return err
+			}
+
+			b, _ := json.Marshal(v)
+			hash := sha256.Sum256(b)
+			if seen[hash] {
+				c.addError(newError(ArrayUniqueItemError, subPath))
+			}
+			seen[hash] = true
+
+			if one != nil {
+				item := c.child(one, tokens...)
+				item.Validate(v, subPath)
+				c.addErrors(item.Errors())
+			}
+		} else if one != nil {
+			item := c.child(one, tokens...)
+			err := s.validate(item, tok, subPath)
+			c.addErrors(item.Errors())
+			if err != nil {
+				return err
+			}
+		} else if err := s.skip(tok); err != nil {
+			return err
+		}
+
+		if err := s.stop(c); err != nil {
+			return err
+		}
+	}
+
+	// the closing ']'
+	if _, err := s.token(); err != nil {
+		return err
+	}
+
+	// as in ArrayConstraint, from the number of items
+	for _, keyword := range []string{"maxItems", "minItems", "uniqueItems", "items"} {
+		if _, ok := c.schema[keyword]; !ok || c.stopped() {
+			continue
+		}
+		switch keyword {
+		case "maxItems":
+			if max, _ := c.schema.MaxItems(); count > max {
+				c.addError(newError(ArrayMaxItemError, path).withParam("limit", max))
+			}
+		case "minItems":
+			if min, _ := c.schema.MinItems(); count < min {
+				c.addError(newError(ArrayMinItemError, path).withParam("limit", min))
+			}
+		}
+		c.evaluated(path, keyword)
+	}
+
+	return s.stop(c)
+}
+
+func (s *streamDecoder) validateObject(c *baseConstraint, path string) error {
+	// only the required properties are remembered
+	required, _ := c.schema.Required()
+	present := make(map[string]bool, len(required))
+	for _, prop := range required {
+		present[prop] = false
+	}
+
+	count := 0
+	for ; s.dec.More(); count++ {
+		tok, err := s.token()
+		if err != nil {
+			return err
+		}
+		prop, _ := tok.(string)
+		subPath := fmt.Sprintf("%s.%s", path, prop)
+		if _, ok := present[prop]; ok {
+			present[prop] = true
+		}
+
+		if tok, err = s.token(); err != nil {
+			return err
+		}
+
+		if c.stopped() {
+			if err := s.skip(tok); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// the patterns are matched with the engine of the validation, as in
+		// ObjectConstraint
+		schemas, tokens, allowed := c.schema.propertySchemas(prop, func(pattern string) bool {
+			matched, _ := c.match(pattern, prop, subPath)
+			return matched
+		})
+		switch {
+		case !allowed:
+			c.addError(newError(ObjectUndefinedPropertyError, subPath))
+			err = s.skip(tok)
+		case len(schemas) == 0:
+			err = s.skip(tok)
+		case len(schemas) == 1:
+			one := c.child(schemas[0], tokens[0]...)
+			err = s.validate(one, tok, subPath)
+			c.addErrors(one.Errors())
+		default:
+			// the value is validated against several schemas, so it is materialized
+			var v interface{}
+			if v, err = s.value(tok); err == nil {
+				for i, schema := range schemas {
+					one := c.child(schema, tokens[i]...)
+					one.Validate(v, subPath)
+					c.addErrors(one.Errors())
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.stop(c); err != nil {
+			return err
+		}
+	}
+
+	// the closing '}'
+	if _, err := s.token(); err != nil {
+		return err
+	}
+
+	// as in ObjectConstraint, from the number of properties and the required ones
+	for _, keyword := range []string{"maxProperties", "minProperties", "required", "properties"} {
+		if _, ok := c.schema[keyword]; !ok || c.stopped() {
+			continue
+		}
+		switch keyword {
+		case "maxProperties":
+			if max, _ := c.schema.MaxProperties(); count > max {
+				c.addError(newError(ObjectMaxPropertiesError, path).withParam("limit", max))
+			}
+		case "minProperties":
+			if min, _ := c.schema.MinProperties(); count < min {
+				c.addError(newError(ObjectMinPropertiesError, path).withParam("limit", min))
+			}
+		case "required":
+			for _, prop := range required {
+				if !present[prop] {
+					c.addError(newError(ObjectRequiredPropertiesError, path).withParam("property", prop))
+				}
+			}
+		}
+		c.evaluated(path, keyword)
+	}
+
+	return s.stop(c)
+}
+
+// value materializes the value starting with tok.
+func (s *streamDecoder) value(tok json.Token) (interface{}, error) {
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	if delim == '[' {
+		arr := []interface{}{}
+		for s.dec.More() {
+			tok, err := s.token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := s.value(tok)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err := s.token()
+		return arr, err
+	}
+
+	obj := make(map[string]interface{})
+	for s.dec.More() {
+		tok, err := s.token()
+		if err != nil {
+			return nil, err
+		}
+		prop, _ := tok.(string)
+
+		if tok, err = s.token(); err != nil {
+			return nil, err
+		}
+		v, err := s.value(tok)
+		if err != nil {
+			return nil, err
+		}
+		obj[prop] = v
+	}
+	_, err := s.token()
+	return obj, err
+}
+
+// skip reads the value starting with tok without keeping it.
+func (s *streamDecoder) skip(tok json.Token) error {
+	if _, ok := tok.(json.Delim); !ok {
+		return nil
+	}
+
+	for depth := 1; depth > 0; {
+		tok, err := s.token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			if delim == '[' || delim == '{' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+	}
+	return nil
+}
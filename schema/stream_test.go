@@ -0,0 +1,116 @@
+package schema
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func errorStrings(errs []SchemaError) []string {
+	var strs []string
+	for _, e := range errs {
+		strs = append(strs, e.Error())
+	}
+	sort.Strings(strs)
+	return strs
+}
+
+func TestValidateStream(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"type": "object",
+		"required": ["id", "tags", "items"],
+		"maxProperties": 4,
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"tags": {
+				"type": "array",
+				"uniqueItems": true,
+				"items": {"type": "string", "maxLength": 3}
+			},
+			"items": {
+				"type": "array",
+				"maxItems": 2,
+				"items": {
+					"type": "object",
+					"properties": {
+						"kind": {"enum": ["a", "b"]},
+						"value": {"anyOf": [{"type": "integer"}, {"type": "string"}]}
+					},
+					"additionalProperties": false
+				}
+			}
+		},
+		"patternProperties": {
+			"^x-": {"type": "string"}
+		}
+	}
+	`)
+	assert.NoError(t, err)
+
+	docs := []string{
+		`{"id": 1, "tags": ["a", "b"], "items": [{"kind": "a", "value": 1}]}`,
+		`{"id": 0, "tags": ["a", "a", "long"], "items": [{"kind": "c"}, {"value": true, "other": 1}, {}], "x-a": 1}`,
+		`{"tags": [], "items": [[1, 2], {"kind": {"nested": [1, {"a": 2}]}}], "x-b": "ok", "z": null}`,
+		`[1, 2]`,
+		`"str"`,
+	}
+
+	v := NewValidator(s)
+	for _, doc := range docs {
+		instance, err := deserializeValue(doc)
+		assert.NoError(t, err)
+		expected := v.Validate(instance)
+
+		actual, err := v.ValidateReader(strings.NewReader(doc))
+		assert.NoError(t, err)
+		assert.Equal(t, errorStrings(expected.Errors), errorStrings(actual.Errors), doc)
+	}
+}
+
+func TestValidateStreamInvalidJSON(t *testing.T) {
+	v := NewValidator(Schema{"items": map[string]interface{}{"type": "integer"}})
+
+	_, err := v.ValidateReader(strings.NewReader(`[1, 2,`))
+	assert.Error(t, err)
+
+	// the document is not read past the first error in fail-fast mode
+	res, err := v.ValidateReader(strings.NewReader(`[1, "a", 2,`), WithFailFast())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Error: not match type, Path: $[1]"}, errorStrings(res.Errors))
+
+	// the reader holds a single document
+	for _, doc := range []string{"[1] ]]] garbage", "[1] [2]", "[1] 2"} {
+		_, err = v.ValidateReader(strings.NewReader(doc))
+		assert.Error(t, err, doc)
+	}
+	res, err = v.ValidateReader(strings.NewReader("[1]\n\t "))
+	assert.NoError(t, err)
+	assert.True(t, res.Valid())
+}
+
+func TestValidateStreamDecoder(t *testing.T) {
+	v := NewValidator(Schema{"type": "integer", "maximum": json.Number("10")})
+
+	// numbers are handled without UseNumber too
+	dec := json.NewDecoder(strings.NewReader(`5 11 2.5`))
+	var valid []bool
+	for dec.More() {
+		res, err := v.ValidateStream(dec)
+		assert.NoError(t, err)
+		valid = append(valid, res.Valid())
+	}
+	assert.Equal(t, []bool{true, false, false}, valid)
+}
+
+func deserializeValue(str string) (interface{}, error) {
+	var v interface{}
+	decoder := json.NewDecoder(strings.NewReader(str))
+	decoder.UseNumber()
+	err := decoder.Decode(&v)
+
+	return v, err
+}
@@ -1,6 +1,10 @@
 package schema
 
-import "regexp"
+import (
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
 
 type StringConstraint struct {
 	schema Schema
@@ -15,23 +19,39 @@ func NewStringConstraint(schema Schema) *StringConstraint {
 
 func (constraint *StringConstraint) Validate(v interface{}, path string) {
 	str := v.(string)
-	strLen := len(str)
+	if !utf8.ValidString(str) {
+		constraint.addError(newError(StringInvalidUTF8Error, path))
+		return
+	}
+	strLen := constraint.length(str)
 
 	if maxLen, ok := constraint.schema.MaxLength(); ok {
 		if strLen > maxLen {
-			constraint.addError(newError(StringMaxLengthError, path))
+			constraint.addError(newError(StringMaxLengthError, path).withParam("limit", maxLen))
 		}
+		constraint.evaluated(path, "maxLength")
 	}
 
 	if minLen, ok := constraint.schema.MinLength(); ok {
 		if strLen < minLen {
-			constraint.addError(newError(StringMinLengthError, path))
+			constraint.addError(newError(StringMinLengthError, path).withParam("limit", minLen))
 		}
+		constraint.evaluated(path, "minLength")
 	}
 
 	if pattern, ok := constraint.schema.Pattern(); ok {
-		if !regexp.MustCompile(pattern).MatchString(str) {
-			constraint.addError(newError(StringPatternError, path))
+		if matched, ok := constraint.match(pattern, str, path); ok && !matched {
+			constraint.addError(newError(StringPatternError, path).withParam("pattern", pattern))
 		}
+		constraint.evaluated(path, "pattern")
+	}
+}
+
+// length returns the length of str for maxLength and minLength, in code points,
+// or in grapheme clusters with the grapheme clusters option.
+func (constraint *StringConstraint) length(str string) int {
+	if constraint.opts != nil && constraint.opts.graphemeClusters {
+		return uniseg.GraphemeClusterCount(str)
 	}
+	return utf8.RuneCountInString(str)
 }
@@ -23,8 +23,8 @@ func TestStringConstraint(t *testing.T) {
 			},
 
 			expected: []SchemaError{
-				&schemaError{StringMinLengthError, "a"},
-				&schemaError{StringPatternError, "a"},
+				newError(StringMinLengthError, "a").withParam("limit", 2),
+				newError(StringPatternError, "a").withParam("pattern", "foo(\\d+)"),
 			},
 		},
 	}
@@ -35,3 +35,39 @@ func TestStringConstraint(t *testing.T) {
 		assert.Equal(t, test.expected, constraint.Errors())
 	}
 }
+
+func TestStringLength(t *testing.T) {
+	s := Schema{
+		"maxLength": json.Number("5"),
+		"minLength": json.Number("5"),
+	}
+
+	tests := []struct {
+		str  string
+		opts []Option
+
+		expected []SchemaError
+	}{
+		{str: "naïve"},
+		{str: "日本語の名"},
+		{
+			str:      "e\u0301tude",
+			expected: []SchemaError{newError(StringMaxLengthError, "$").withParam("limit", 5)},
+		},
+		{str: "e\u0301tude", opts: []Option{WithGraphemeClusters()}},
+		{
+			str:      "🇫🇷🇩🇪",
+			opts:     []Option{WithGraphemeClusters()},
+			expected: []SchemaError{newError(StringMinLengthError, "$").withParam("limit", 5)},
+		},
+		{
+			str:      "na\xffve",
+			expected: []SchemaError{newError(StringInvalidUTF8Error, "$")},
+		},
+	}
+
+	for _, test := range tests {
+		res := NewValidator(s, test.opts...).Validate(test.str)
+		assert.Equal(t, test.expected, res.Errors, test.str)
+	}
+}
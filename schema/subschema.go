@@ -0,0 +1,544 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Reason tells why IsSubschema could not prove that a schema is a subschema of
+// another.
+type Reason struct {
+	// Pointer is the JSON pointer of the subschemas compared, the $refs on the
+	// way being followed.
+	Pointer string
+	Message string
+	// Unknown is set when IsSubschema could not decide, rather than finding
+	// values accepted by a and rejected by b.
+	Unknown bool
+}
+
+func (r Reason) String() string {
+	if r.Unknown {
+		return fmt.Sprintf("#%s: %s (unknown)", r.Pointer, r.Message)
+	}
+	return fmt.Sprintf("#%s: %s", r.Pointer, r.Message)
+}
+
+// opaqueKeywords are the keywords restricting the values of a schema in a way
+// IsSubschema does not follow, so that it can not tell for sure that a value
+// is accepted.
+var opaqueKeywords = []string{
+	"allOf", "anyOf", "contains", "contentEncoding", "contentMediaType",
+	"dependencies", "else", "format", "if", "multipleOf", "not", "oneOf",
+	"pattern", "patternProperties", "propertyNames", "then",
+}
+
+// subschemaKeywords are the keywords of b IsSubschema compares by their meaning,
+// or that do not constrain the values.
+var subschemaKeywords = []string{
+	"$anchor", "$comment", "$defs", "$id", "$ref", "$schema", "additionalItems",
+	"additionalProperties", "allOf", "anyOf", "const", "default", "definitions",
+	"deprecated", "description", "enum", "errorMessage", "examples",
+	"exclusiveMaximum", "exclusiveMinimum", "id", "items", "maxItems", "maxLength",
+	"maxProperties", "maximum", "minItems", "minLength", "minProperties",
+	"minimum", "multipleOf", "oneOf", "properties", "readOnly", "required",
+	"title", "type", "uniqueItems", "writeOnly",
+}
+
+// IsSubschema reports whether every value valid against a is valid against b.
+// The check is best-effort: it covers the types, the numeric ranges, the
+// lengths, enum and const, required and properties, items, and allOf, anyOf and
+// oneOf in simple cases. It returns true only if it proves it, otherwise false
+// and the reasons, which are all Unknown if it could not decide. Both schemas
+// follow their $refs into the documents given with WithSchema, or loaded when
+// WithRemoteRefs or WithFileRefs allows it.
+func IsSubschema(a Schema, b Schema, opts ...Option) (bool, []Reason) {
+	s := &subsumption{
+		a:        NewValidator(a, opts...).options(nil),
+		b:        NewValidator(b, opts...).options(nil),
+		visiting: make(map[[2]uintptr]bool),
+	}
+	reasons := s.check(a, schemaBase("", a), b, schemaBase("", b), "")
+	return len(reasons) == 0, reasons
+}
+
+type subsumption struct {
+	a *options
+	b *options
+	// visiting are the pairs of subschemas being checked, which are assumed to
+	// be subschemas when met again.
+	visiting map[[2]uintptr]bool
+}
+
+// scope is a subschema with its base URI, and the options to validate against
+// it.
+type scope struct {
+	schema Schema
+	base   string
+	opts   *options
+}
+
+// follow returns the scope of the schema the $refs of the schema point to.
+func (sc scope) follow() scope {
+	sc.schema, sc.base = sc.opts.refs.follow(sc.schema, sc.base, sc.opts.maxRefDepth)
+	return sc
+}
+
+// sub returns the scope of the subschema sub.
+func (sc scope) sub(sub Schema) scope {
+	return scope{schema: sub, base: schemaBase(sc.base, sub), opts: sc.opts}
+}
+
+// accepts reports whether v is valid against the schema.
+func (sc scope) accepts(v interface{}) bool {
+	o := *sc.opts
+	o.failFast = true
+	o.probing = true
+	o.errorCount = new(int64)
+
+	c := NewBaseConstraint(sc.schema)
+	c.opts = &o
+	c.base = sc.base
+	c.Validate(v, rootPath)
+	return len(c.errors) == 0
+}
+
+// opaque reports whether the schema has keywords IsSubschema does not follow.
+func (sc scope) opaque() bool {
+	for _, keyword := range opaqueKeywords {
+		if _, ok := sc.schema[keyword]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *subsumption) check(a Schema, aBase string, b Schema, bBase string, pointer string) []Reason {
+	return s.checkScopes(scope{a, aBase, s.a}, scope{b, bBase, s.b}, pointer)
+}
+
+func (s *subsumption) checkScopes(a scope, b scope, pointer string) []Reason {
+	a, b = a.follow(), b.follow()
+
+	pair := [2]uintptr{reflect.ValueOf(a.schema).Pointer(), reflect.ValueOf(b.schema).Pointer()}
+	if s.visiting[pair] {
+		return nil
+	}
+	s.visiting[pair] = true
+	defer delete(s.visiting, pair)
+
+	c := &subsumptionCheck{s: s, a: a, b: b, pointer: pointer}
+	if values, ok := finiteValues(a); ok {
+		c.checkValues(values)
+		return c.reasons
+	}
+	c.checkType()
+	c.checkEnum()
+	c.checkNumbers()
+	c.checkStrings()
+	c.checkArrays()
+	c.checkObjects()
+	c.checkComposition()
+	c.checkOtherKeywords()
+
+	if len(c.reasons) == 0 {
+		return nil
+	}
+	return s.checkBranches(a, b, pointer, c.reasons)
+}
+
+// checkBranches checks whether a is a subschema of b through the subschemas of
+// its allOf, anyOf and oneOf, reasons being the ones found without them.
+func (s *subsumption) checkBranches(a scope, b scope, pointer string, reasons []Reason) []Reason {
+	// a is a subschema of b if one of its allOf subschemas is
+	if branches, ok := a.schema.AllOf(); ok {
+		for _, branch := range branches {
+			if len(s.checkScopes(a.sub(branch), b, pointer)) == 0 {
+				return nil
+			}
+		}
+	}
+
+	// or if all of its anyOf or oneOf subschemas are
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		branches, _ := a.schema[keyword].([]interface{})
+		all := len(branches) > 0
+		for _, branch := range branches {
+			one, ok := branch.(map[string]interface{})
+			all = all && ok && len(s.checkScopes(a.sub(Schema(one)), b, pointer)) == 0
+		}
+		if all {
+			return nil
+		}
+	}
+	return reasons
+}
+
+// finiteValues returns the values a accepts, if they are few.
+func finiteValues(a scope) ([]interface{}, bool) {
+	var candidates []interface{}
+	if v, ok := a.schema["const"]; ok {
+		candidates = []interface{}{v}
+	} else if values, ok := a.schema["enum"].([]interface{}); ok {
+		candidates = values
+	} else {
+		for _, t := range allTypes {
+			if accepts(a.schema, t) && t != JsonNull && t != JsonBoolean {
+				return nil, false
+			}
+		}
+		candidates = []interface{}{nil, false, true}
+	}
+
+	var values []interface{}
+	for _, v := range candidates {
+		if a.accepts(v) {
+			values = append(values, v)
+		}
+	}
+	return values, true
+}
+
+// subsumptionCheck checks whether a is a subschema of b, without following
+// their allOf, anyOf and oneOf.
+type subsumptionCheck struct {
+	s       *subsumption
+	a       scope
+	b       scope
+	pointer string
+	reasons []Reason
+}
+
+// report adds the reason why a is not a subschema of b, which is unknown if a
+// has keywords restricting its values that are not followed.
+func (c *subsumptionCheck) report(unknown bool, format string, args ...interface{}) {
+	c.reasons = append(c.reasons, Reason{
+		Pointer: c.pointer,
+		Message: fmt.Sprintf(format, args...),
+		Unknown: unknown || c.a.opaque(),
+	})
+}
+
+// merge adds the reasons of a subschema check.
+func (c *subsumptionCheck) merge(reasons []Reason) {
+	c.reasons = append(c.reasons, reasons...)
+}
+
+// allows reports whether a accepts some values of t.
+func (c *subsumptionCheck) allows(t JsonType) bool {
+	return accepts(c.a.schema, t)
+}
+
+func (c *subsumptionCheck) checkValues(values []interface{}) {
+	for _, v := range values {
+		if !c.b.accepts(v) {
+			c.reasons = append(c.reasons, Reason{Pointer: c.pointer, Message: fmt.Sprintf("a allows %s, b does not", jsonString(v))})
+		}
+	}
+}
+
+func (c *subsumptionCheck) checkType() {
+	for _, t := range allTypes {
+		if accepts(c.a.schema, t) && !accepts(c.b.schema, t) {
+			if t == JsonNumber {
+				c.report(false, "a allows numbers that are not integers, b does not")
+			} else {
+				c.report(false, "a allows %s values, b does not", t)
+			}
+		}
+	}
+}
+
+func (c *subsumptionCheck) checkEnum() {
+	if values, keyword, ok := enumValues(c.b.schema); ok {
+		c.report(false, "b only allows the values of %s %s, a allows others", keyword, jsonString(values))
+	}
+}
+
+// bound is the upper or lower bound of the numbers a schema accepts.
+type bound struct {
+	value     float64
+	exclusive bool
+	exist     bool
+}
+
+func (bd bound) describe(upper bool) string {
+	switch {
+	case !bd.exist:
+		return "without bound"
+	case upper && bd.exclusive:
+		return fmt.Sprintf("< %v", bd.value)
+	case upper:
+		return fmt.Sprintf("<= %v", bd.value)
+	case bd.exclusive:
+		return fmt.Sprintf("> %v", bd.value)
+	default:
+		return fmt.Sprintf(">= %v", bd.value)
+	}
+}
+
+// numberBound returns the upper or lower bound of s, from maximum or minimum
+// and exclusiveMaximum or exclusiveMinimum, which are booleans in draft 4.
+func numberBound(s Schema, upper bool) bound {
+	inclusive, exclusive := "minimum", "exclusiveMinimum"
+	if upper {
+		inclusive, exclusive = "maximum", "exclusiveMaximum"
+	}
+
+	var bd bound
+	if v, ok := s[inclusive]; ok && isNumber(v) {
+		bd = bound{value: toFloat(v), exist: true}
+		if flag, _ := s[exclusive].(bool); flag {
+			bd.exclusive = true
+		}
+	}
+	if v, ok := s[exclusive]; ok && isNumber(v) {
+		ex := bound{value: toFloat(v), exclusive: true, exist: true}
+		if !bd.exist || (upper && ex.value <= bd.value) || (!upper && ex.value >= bd.value) {
+			bd = ex
+		}
+	}
+	return bd
+}
+
+// within reports whether the numbers within bd are within other.
+func (bd bound) within(other bound, upper bool) bool {
+	switch {
+	case !other.exist:
+		return true
+	case !bd.exist:
+		return false
+	case bd.value == other.value:
+		return bd.exclusive || !other.exclusive
+	case upper:
+		return bd.value < other.value
+	default:
+		return bd.value > other.value
+	}
+}
+
+func (c *subsumptionCheck) checkNumbers() {
+	if !c.allows(JsonInteger) && !c.allows(JsonNumber) {
+		return
+	}
+
+	for _, upper := range []bool{true, false} {
+		aBound, bBound := numberBound(c.a.schema, upper), numberBound(c.b.schema, upper)
+		if !aBound.within(bBound, upper) {
+			c.report(false, "a allows numbers %s, b only %s", aBound.describe(upper), bBound.describe(upper))
+		}
+	}
+
+	if v, ok := c.b.schema["multipleOf"]; ok && isNumber(v) {
+		bMultiple := toFloat(v)
+		aMultiple := toFloat(c.a.schema["multipleOf"])
+		if aMultiple == 0 && !c.allows(JsonNumber) {
+			aMultiple = 1
+		}
+		if q := aMultiple / bMultiple; aMultiple == 0 || q != math.Trunc(q) {
+			c.report(false, "a allows numbers that are not multiples of %v", v)
+		}
+	}
+}
+
+// checkLengths checks the lengths bounded by the keywords max and min, of the
+// values of t.
+func (c *subsumptionCheck) checkLengths(t JsonType, max string, min string) {
+	if !c.allows(t) {
+		return
+	}
+
+	if v, ok := c.b.schema[max]; ok && isNumber(v) {
+		if w, ok := maxLimit(c.a.schema, max); !ok {
+			c.report(false, "a has no %s, b has %v", max, v)
+		} else if w > toFloat(v) {
+			c.report(false, "%s %v of a is greater than the one of b, %v", max, w, v)
+		}
+	}
+	if v, ok := c.b.schema[min]; ok && isNumber(v) && toFloat(v) > 0 {
+		if w, ok := c.a.schema[min]; !ok || !isNumber(w) {
+			c.report(false, "a has no %s, b has %v", min, v)
+		} else if toFloat(w) < toFloat(v) {
+			c.report(false, "%s %v of a is less than the one of b, %v", min, w, v)
+		}
+	}
+}
+
+// maxLimit returns the upper bound of the lengths of s set by the keyword max,
+// or for maxItems by a tuple without additional items.
+func maxLimit(s Schema, max string) (float64, bool) {
+	limit, exist := 0.0, false
+	if v, ok := s[max]; ok && isNumber(v) {
+		limit, exist = toFloat(v), true
+	}
+	if max == "maxItems" {
+		_, tuple, _ := s.Items()
+		if _, _, allowed := itemSchema(s, len(tuple)); !allowed && (!exist || float64(len(tuple)) < limit) {
+			limit, exist = float64(len(tuple)), true
+		}
+	}
+	return limit, exist
+}
+
+func (c *subsumptionCheck) checkStrings() {
+	c.checkLengths(JsonString, "maxLength", "minLength")
+}
+
+func (c *subsumptionCheck) checkArrays() {
+	c.checkLengths(JsonArray, "maxItems", "minItems")
+	if !c.allows(JsonArray) {
+		return
+	}
+
+	if unique, _ := c.b.schema["uniqueItems"].(bool); unique {
+		if aUnique, _ := c.a.schema["uniqueItems"].(bool); !aUnique {
+			c.report(false, "a allows duplicate items, b does not")
+		}
+	}
+
+	_, bTuple, bExist := c.b.schema.Items()
+	if !bExist {
+		return
+	}
+
+	// the items up to the longest tuple are compared one by one, and the
+	// following ones as a whole
+	_, aTuple, _ := c.a.schema.Items()
+	n := len(aTuple)
+	if len(bTuple) > n {
+		n = len(bTuple)
+	}
+	for i := 0; i <= n; i++ {
+		pointer := fmt.Sprintf("%s/items/%d", c.pointer, i)
+		if i == n {
+			pointer = c.pointer + "/items"
+		}
+		if max, ok := maxLimit(c.a.schema, "maxItems"); ok && float64(i) >= max {
+			break
+		}
+
+		bItem, _, bAllows := itemSchema(c.b.schema, i)
+		aItem, _, _ := itemSchema(c.a.schema, i)
+		switch {
+		case !bAllows:
+			c.report(false, "a allows more than %d items, b does not", i)
+			return
+		case bItem == nil:
+		case aItem == nil:
+			c.report(false, "a allows any item %d, b does not", i)
+		default:
+			c.merge(c.s.checkScopes(c.a.sub(aItem), c.b.sub(bItem), pointer))
+		}
+	}
+}
+
+func (c *subsumptionCheck) checkObjects() {
+	c.checkLengths(JsonObject, "maxProperties", "minProperties")
+	if !c.allows(JsonObject) {
+		return
+	}
+
+	aRequired, _ := c.a.schema.Required()
+	bRequired, _ := c.b.schema.Required()
+	for _, prop := range bRequired {
+		if !containsString(aRequired, prop) {
+			c.report(false, "b requires the property %s, a does not", prop)
+		}
+	}
+
+	aProps, _ := c.a.schema.Properties()
+	bProps, _ := c.b.schema.Properties()
+	aAddition, aAllowAddition, aAdditionExist := c.a.schema.AdditionalProperties()
+	bAddition, bAllowAddition, bAdditionExist := c.b.schema.AdditionalProperties()
+	aForbids := aAdditionExist && aAddition == nil && !aAllowAddition
+	bForbids := bAdditionExist && bAddition == nil && !bAllowAddition
+
+	// the properties of b, which a declares or not
+	for _, prop := range sortedKeys(propertyKeys(bProps)) {
+		pointer := c.pointer + "/properties/" + escapePointer(prop)
+		if aProp, ok := aProps[prop]; ok {
+			c.merge(c.s.checkScopes(c.a.sub(aProp), c.b.sub(bProps[prop]), pointer))
+		} else if aAddition != nil {
+			c.merge(c.s.checkScopes(c.a.sub(aAddition), c.b.sub(bProps[prop]), pointer))
+		} else if !aForbids && len(bProps[prop]) > 0 {
+			c.report(false, "a allows any property %s, b does not", prop)
+		}
+	}
+
+	// the other properties of a, which b does not declare
+	if !bAdditionExist || (bAddition == nil && bAllowAddition) {
+		return
+	}
+	for _, prop := range sortedKeys(propertyKeys(aProps)) {
+		if _, ok := bProps[prop]; ok {
+			continue
+		}
+		if bForbids {
+			c.report(false, "a allows the property %s, b does not", prop)
+		} else {
+			c.merge(c.s.checkScopes(c.a.sub(aProps[prop]), c.b.sub(bAddition), c.pointer+"/properties/"+escapePointer(prop)))
+		}
+	}
+	switch {
+	case aForbids:
+	case aAddition != nil && bAddition != nil:
+		c.merge(c.s.checkScopes(c.a.sub(aAddition), c.b.sub(bAddition), c.pointer+"/additionalProperties"))
+	case bForbids:
+		c.report(false, "a allows additional properties, b does not")
+	default:
+		c.report(false, "a allows any additional property, b does not")
+	}
+}
+
+// propertyKeys returns props as a Schema, for sortedKeys.
+func propertyKeys(props map[string]Schema) Schema {
+	keys := make(Schema, len(props))
+	for prop := range props {
+		keys[prop] = nil
+	}
+	return keys
+}
+
+func (c *subsumptionCheck) checkComposition() {
+	// a must be a subschema of every allOf subschema of b
+	if branches, ok := c.b.schema.AllOf(); ok {
+		for i, branch := range branches {
+			c.merge(c.s.checkScopes(c.a, c.b.sub(branch), fmt.Sprintf("%s/allOf/%d", c.pointer, i)))
+		}
+	}
+
+	// and of one of its anyOf subschemas, or of its only oneOf subschema
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		branches, ok := c.b.schema[keyword].([]interface{})
+		if !ok || reflect.DeepEqual(c.a.schema[keyword], c.b.schema[keyword]) {
+			continue
+		}
+		if keyword == "oneOf" && len(branches) > 1 {
+			c.report(true, "can not tell whether the values of a match only one oneOf subschema of b")
+			continue
+		}
+
+		found := false
+		for _, branch := range branches {
+			if one, ok := branch.(map[string]interface{}); ok && len(c.s.checkScopes(c.a, c.b.sub(Schema(one)), c.pointer)) == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.report(true, "a is not a subschema of any %s subschema of b", keyword)
+		}
+	}
+}
+
+// checkOtherKeywords checks the keywords of b that are not followed, which a
+// must have with the same value.
+func (c *subsumptionCheck) checkOtherKeywords() {
+	for _, keyword := range sortedKeys(c.b.schema) {
+		if containsString(subschemaKeywords, keyword) || reflect.DeepEqual(c.a.schema[keyword], c.b.schema[keyword]) {
+			continue
+		}
+		c.report(true, "can not tell whether the values of a satisfy %s of b", keyword)
+	}
+}
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSubschema(t *testing.T) {
+	tests := []struct {
+		a       string
+		b       string
+		reasons []Reason
+	}{
+		{a: `{"type": "integer"}`, b: `{"type": "number"}`},
+		{a: `{}`, b: `{}`},
+		{
+			a:       `{"type": ["string", "null"]}`,
+			b:       `{"type": "string"}`,
+			reasons: []Reason{{"", "a allows null values, b does not", false}},
+		},
+		{
+			a:       `{"type": "number"}`,
+			b:       `{"type": "integer"}`,
+			reasons: []Reason{{"", "a allows numbers that are not integers, b does not", false}},
+		},
+		{a: `{"type": "number", "minimum": 1, "exclusiveMaximum": 5}`, b: `{"minimum": 0, "maximum": 5}`},
+		{
+			a: `{"type": "number", "minimum": 0, "maximum": 5}`,
+			b: `{"exclusiveMinimum": 0, "maximum": 4}`,
+			reasons: []Reason{
+				{"", "a allows numbers <= 5, b only <= 4", false},
+				{"", "a allows numbers >= 0, b only > 0", false},
+			},
+		},
+		{a: `{"type": "integer", "multipleOf": 4}`, b: `{"multipleOf": 2}`},
+		{a: `{"type": "integer"}`, b: `{"multipleOf": 1}`},
+		{a: `{"type": "string", "minLength": 2, "maxLength": 3}`, b: `{"minLength": 1, "maxLength": 3}`},
+		{
+			a:       `{"type": "string", "maxLength": 4}`,
+			b:       `{"maxLength": 3, "minLength": 1}`,
+			reasons: []Reason{{"", "maxLength 4 of a is greater than the one of b, 3", false}, {"", "a has no minLength, b has 1", false}},
+		},
+		{a: `{"enum": ["a", "b"]}`, b: `{"enum": ["a", "b", "c"]}`},
+		{a: `{"enum": ["a", 1]}`, b: `{"type": "string"}`, reasons: []Reason{{"", "a allows 1, b does not", false}}},
+		{a: `{"type": "boolean"}`, b: `{"enum": [true, false]}`},
+		{
+			a:       `{"type": "string"}`,
+			b:       `{"enum": ["a"]}`,
+			reasons: []Reason{{"", `b only allows the values of enum ["a"], a allows others`, false}},
+		},
+		{
+			a: `{"type": "object", "properties": {"a": {"type": "integer"}, "b": {}}, "required": ["a", "b"], "additionalProperties": false}`,
+			b: `{"type": "object", "properties": {"a": {"type": "number"}, "b": {}}, "required": ["a"], "additionalProperties": false}`,
+		},
+		{
+			a: `{"type": "object", "properties": {"a": {"type": "string"}, "c": {}}}`,
+			b: `{"type": "object", "properties": {"a": {"type": "integer"}, "b": {"type": "string"}}, "required": ["a"], "additionalProperties": false}`,
+			reasons: []Reason{
+				{"", "b requires the property a, a does not", false},
+				{"/properties/a", "a allows string values, b does not", false},
+				{"", "a allows any property b, b does not", false},
+				{"", "a allows the property c, b does not", false},
+				{"", "a allows additional properties, b does not", false},
+			},
+		},
+		{a: `{"type": "array", "items": {"type": "integer"}, "uniqueItems": true}`, b: `{"items": {"type": "number"}}`},
+		{a: `{"type": "array", "items": [{"type": "string"}], "additionalItems": false}`, b: `{"items": {"type": "string"}, "maxItems": 1}`},
+		{
+			a:       `{"type": "array", "items": [{"type": "string"}, {"type": "null"}]}`,
+			b:       `{"items": [{"type": "string"}], "additionalItems": false}`,
+			reasons: []Reason{{"", "a allows more than 1 items, b does not", false}},
+		},
+		{a: `{"allOf": [{"type": "string"}, {"maxLength": 3}]}`, b: `{"type": "string"}`},
+		{a: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, b: `{"type": ["string", "number"]}`},
+		{a: `{"type": "string"}`, b: `{"anyOf": [{"type": "integer"}, {"type": "string"}]}`},
+		{
+			a:       `{"type": "string"}`,
+			b:       `{"oneOf": [{"type": "string"}, {"maxLength": 3}]}`,
+			reasons: []Reason{{"", "can not tell whether the values of a match only one oneOf subschema of b", true}},
+		},
+		{
+			a:       `{"type": "string", "pattern": "^a"}`,
+			b:       `{"type": "string", "pattern": "^[ab]"}`,
+			reasons: []Reason{{"", "can not tell whether the values of a satisfy pattern of b", true}},
+		},
+		{
+			// the $refs are followed, and the recursive schemas checked once
+			a: `{"$ref": "#/definitions/node", "definitions": {"node": {"type": "object", "properties": {"next": {"$ref": "#/definitions/node"}}, "required": ["next"]}}}`,
+			b: `{"type": "object", "properties": {"next": {"$ref": "#"}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		a, err := deserializeSchema(test.a)
+		assert.NoError(t, err)
+		b, err := deserializeSchema(test.b)
+		assert.NoError(t, err)
+
+		ok, reasons := IsSubschema(a, b)
+		assert.Equal(t, len(test.reasons) == 0, ok, "%s <= %s", test.a, test.b)
+		assert.Equal(t, test.reasons, reasons, "%s <= %s", test.a, test.b)
+	}
+}
+
+func TestIsSubschemaWitness(t *testing.T) {
+	// IsSubschema agrees with the validation on the items past a tuple
+	tests := []struct {
+		a       string
+		b       string
+		witness string
+	}{
+		{a: `{"items": [{"type": "string"}]}`, b: `{"items": [{"type": "string"}], "additionalItems": false}`, witness: `["a", 1]`},
+		{a: `{"items": [{"type": "string"}], "additionalItems": {"type": "integer"}}`, b: `{"items": [{"type": "string"}]}`, witness: `["a", 1]`},
+	}
+
+	for _, test := range tests {
+		a, err := deserializeSchema(test.a)
+		assert.NoError(t, err)
+		b, err := deserializeSchema(test.b)
+		assert.NoError(t, err)
+		v, err := deserializeValue(test.witness)
+		assert.NoError(t, err)
+
+		validA := NewValidator(a).Validate(v).Valid()
+		validB := NewValidator(b).Validate(v).Valid()
+		assert.True(t, validA, test.a)
+		ok, _ := IsSubschema(a, b)
+		assert.Equal(t, validB, ok, "%s <= %s", test.a, test.b)
+	}
+}
+
+func TestReasonString(t *testing.T) {
+	assert.Equal(t, "#/properties/a: a allows null values, b does not", Reason{"/properties/a", "a allows null values, b does not", false}.String())
+	assert.Equal(t, "#: can not tell (unknown)", Reason{"", "can not tell", true}.String())
+}
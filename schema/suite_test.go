@@ -0,0 +1,318 @@
+package schema
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The JSON-Schema-Test-Suite, https://github.com/json-schema-org/JSON-Schema-Test-Suite,
+// is copied in suiteDir: its tests directory with the cases of every draft,
+// and its remotes directory with the schemas their $refs load from
+// http://localhost:1234. SOURCE.md there records where the copy comes from.
+const (
+	suiteDir     = "testdata/JSON-Schema-Test-Suite"
+	suiteRemote  = "http://localhost:1234/"
+	complianceMD = "../COMPLIANCE.md"
+)
+
+var updateCompliance = flag.Bool("update-compliance", false, "write the results of the JSON-Schema-Test-Suite to "+complianceMD)
+
+// suiteGroup is a schema of the suite, with the cases validated against it.
+type suiteGroup struct {
+	Description string      `json:"description"`
+	Schema      interface{} `json:"schema"`
+	Tests       []struct {
+		Description string      `json:"description"`
+		Data        interface{} `json:"data"`
+		Valid       bool        `json:"valid"`
+	} `json:"tests"`
+}
+
+// suiteResult counts the cases of a keyword of a draft that passed, and
+// describes the ones that failed and, among them, the ones that panicked.
+type suiteResult struct {
+	passed   int
+	total    int
+	failed   []string
+	panicked []string
+}
+
+// suitePanic is the error of a case whose compilation or validation panicked.
+type suitePanic struct {
+	value interface{}
+}
+
+func (p suitePanic) Error() string {
+	return fmt.Sprintf("panic: %v", p.value)
+}
+
+// TestSuite runs the cases of the JSON-Schema-Test-Suite, and fails if a case
+// panics, or if fewer cases pass for a keyword of a draft than reported in
+// COMPLIANCE.md. Run it with -update-compliance to update the report.
+func TestSuite(t *testing.T) {
+	results, err := runSuite(filepath.Join(suiteDir, "tests"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for draft, keywords := range results {
+		for keyword, result := range keywords {
+			if len(result.panicked) > 0 {
+				t.Errorf("%s %s: %d cases panicked:\n%s", draft, keyword, len(result.panicked), strings.Join(result.panicked, "\n"))
+			}
+		}
+	}
+
+	if *updateCompliance {
+		assert.NoError(t, writeCompliance(complianceMD, results))
+		return
+	}
+
+	reported, err := readCompliance(complianceMD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for draft, keywords := range results {
+		for keyword, result := range keywords {
+			if want := reported[draft][keyword]; result.passed < want {
+				t.Errorf("%s %s: %d cases passed, %d before, the failed ones:\n%s",
+					draft, keyword, result.passed, want, strings.Join(result.failed, "\n"))
+			}
+		}
+	}
+}
+
+// runSuite runs the cases of the drafts in dir, and returns their results by
+// draft and keyword. The keyword of the cases of a file is its path in the
+// directory of the draft, without .json.
+func runSuite(dir string) (map[string]map[string]*suiteResult, error) {
+	drafts, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := suiteLoader(filepath.Join(filepath.Dir(dir), "remotes"))
+	results := make(map[string]map[string]*suiteResult)
+	for _, draft := range drafts {
+		if !draft.IsDir() {
+			continue
+		}
+		draftDir := filepath.Join(dir, draft.Name())
+		keywords := make(map[string]*suiteResult)
+		err := filepath.Walk(draftDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || filepath.Ext(path) != ".json" {
+				return err
+			}
+			rel, err := filepath.Rel(draftDir, path)
+			if err != nil {
+				return err
+			}
+			result, err := runSuiteFile(path, loader)
+			if err != nil {
+				return err
+			}
+			keywords[strings.TrimSuffix(filepath.ToSlash(rel), ".json")] = result
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		results[draft.Name()] = keywords
+	}
+	return results, nil
+}
+
+func runSuiteFile(path string, loader Loader) (*suiteResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var groups []suiteGroup
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	if err := dec.Decode(&groups); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	result := &suiteResult{}
+	for _, group := range groups {
+		validator, compileErr := compileSuiteSchema(group.Schema, loader)
+		for _, test := range group.Tests {
+			result.total++
+			valid, err := validateSuiteCase(validator, compileErr, test.Data)
+			if err == nil && valid == test.Valid {
+				result.passed++
+				continue
+			}
+			failure := fmt.Sprintf("  %s: %s", group.Description, test.Description)
+			if err != nil {
+				failure += ": " + err.Error()
+			}
+			result.failed = append(result.failed, failure)
+			if _, ok := err.(suitePanic); ok {
+				result.panicked = append(result.panicked, failure)
+			}
+		}
+	}
+	return result, nil
+}
+
+// compileSuiteSchema compiles a schema of the suite, the boolean schemas of the
+// drafts 6 and later as their equivalent objects.
+func compileSuiteSchema(s interface{}, loader Loader) (validator *Validator, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = suitePanic{r}
+		}
+	}()
+
+	switch s := s.(type) {
+	case bool:
+		if s {
+			return Compile(Schema{}, WithLoader(loader), WithRemoteRefs())
+		}
+		return Compile(Schema{"not": map[string]interface{}{}}, WithLoader(loader), WithRemoteRefs())
+	case map[string]interface{}:
+		return Compile(Schema(s), WithLoader(loader), WithRemoteRefs())
+	}
+	return nil, fmt.Errorf("invalid schema %v", s)
+}
+
+func validateSuiteCase(validator *Validator, compileErr error, data interface{}) (valid bool, err error) {
+	if compileErr != nil {
+		return false, compileErr
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = suitePanic{r}
+		}
+	}()
+	return validator.Validate(data).Valid(), nil
+}
+
+// suiteLoader loads the schemas of suiteRemote from the files of remotes, and
+// no other ones.
+func suiteLoader(remotes string) Loader {
+	return LoaderFunc(func(uri string) (Schema, error) {
+		if !strings.HasPrefix(uri, suiteRemote) {
+			return nil, fmt.Errorf("Load Error: %s is not in the suite", uri)
+		}
+		path, err := filepath.Abs(filepath.Join(remotes, filepath.FromSlash(strings.TrimPrefix(uri, suiteRemote))))
+		if err != nil {
+			return nil, err
+		}
+		return loadURI("file://" + filepath.ToSlash(path))
+	})
+}
+
+// writeCompliance writes the results as a table of the cases passed by
+// keyword for every draft.
+func writeCompliance(path string, results map[string]map[string]*suiteResult) error {
+	var b strings.Builder
+	b.WriteString("# JSON-Schema-Test-Suite compliance\n\n")
+	b.WriteString("The cases of the JSON-Schema-Test-Suite passed by the validator, written by\n")
+	b.WriteString("`go test ./schema -run TestSuite -update-compliance`.\n")
+
+	for _, draft := range sortedResultKeys(results) {
+		passed, total := 0, 0
+		for _, result := range results[draft] {
+			passed += result.passed
+			total += result.total
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n%d of %d cases passed.\n\n", draft, passed, total)
+		b.WriteString("| keyword | passed | total |\n|---|---:|---:|\n")
+
+		keywords := make([]string, 0, len(results[draft]))
+		for keyword := range results[draft] {
+			keywords = append(keywords, keyword)
+		}
+		sort.Strings(keywords)
+		for _, keyword := range keywords {
+			result := results[draft][keyword]
+			fmt.Fprintf(&b, "| %s | %d | %d |\n", keyword, result.passed, result.total)
+		}
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// readCompliance reads the cases passed by draft and keyword from a report
+// written by writeCompliance.
+func readCompliance(path string) (map[string]map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reported := make(map[string]map[string]int)
+	var draft string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "## ") {
+			draft = strings.TrimPrefix(line, "## ")
+			reported[draft] = make(map[string]int)
+			continue
+		}
+		cells := strings.Split(strings.Trim(line, "|"), "|")
+		if draft == "" || len(cells) != 3 {
+			continue
+		}
+		if passed, err := strconv.Atoi(strings.TrimSpace(cells[1])); err == nil {
+			reported[draft][strings.TrimSpace(cells[0])] = passed
+		}
+	}
+	return reported, scanner.Err()
+}
+
+func sortedResultKeys(results map[string]map[string]*suiteResult) []string {
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestCompliance(t *testing.T) {
+	results := map[string]map[string]*suiteResult{
+		"draft7": {
+			"type":            {passed: 80, total: 80},
+			"optional/bignum": {passed: 3, total: 9},
+			"additionalItems": {passed: 14, total: 16},
+		},
+		"draft4": {"ref": {passed: 20, total: 25}},
+	}
+	path := filepath.Join(t.TempDir(), "COMPLIANCE.md")
+	assert.NoError(t, writeCompliance(path, results))
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "## draft7\n\n97 of 105 cases passed.\n\n| keyword | passed | total |\n|---|---:|---:|\n| additionalItems | 14 | 16 |\n| optional/bignum | 3 | 9 |\n| type | 80 | 80 |\n")
+
+	reported, err := readCompliance(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]int{
+		"draft4": {"ref": 20},
+		"draft7": {"type": 80, "optional/bignum": 3, "additionalItems": 14},
+	}, reported)
+}
+
+func TestSuitePanic(t *testing.T) {
+	// a panic is reported as such, and does not stop the suite
+	_, err := validateSuiteCase(nil, nil, "a")
+	assert.IsType(t, suitePanic{}, err)
+	assert.Contains(t, err.Error(), "panic: ")
+}
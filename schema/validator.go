@@ -0,0 +1,137 @@
+package schema
+
+// rootPath is the path of the instance passed to Validator.Validate.
+const rootPath = "$"
+
+// Validator validates instances against a schema.
+type Validator struct {
+	schema  Schema
+	opts    []Option
+	refs    *registry
+	regexps *regexpCache
+}
+
+// NewValidator returns a Validator for s. The options apply to every call to
+// Validate, and can be overridden per call. The $refs of s are resolved when
+// they are first validated, see Compile to resolve them upfront.
+func NewValidator(s Schema, opts ...Option) *Validator {
+	o := newOptions(opts...)
+	return &Validator{
+		schema:  s,
+		opts:    opts,
+		refs:    newRegistry(s, o),
+		regexps: newRegexpCache(o.regexpEngine, o.maxPatternLength, 0),
+	}
+}
+
+// Validate validates v and returns the result. The options passed here take
+// precedence over the ones given to NewValidator.
+func (validator *Validator) Validate(v interface{}, opts ...Option) *Result {
+	o := validator.options(opts)
+
+	if o.maxDepth > 0 {
+		if path, ok := tooDeep(v, rootPath, 0, o.maxDepth); ok {
+			return &Result{
+				Errors:    []SchemaError{newError(MaxDepthError, path).withParam("limit", o.maxDepth)},
+				Value:     v,
+				formatter: o.formatter,
+			}
+		}
+	}
+
+	var removed []string
+	if o.removeAdditional {
+		v, removed = validator.sanitize(v, o)
+	}
+
+	c := validator.root(o)
+	c.Validate(v, rootPath)
+	c.addContextError(rootPath)
+	c.addLimitError(rootPath)
+	if c.replaced {
+		v = c.replacement
+	}
+
+	return &Result{
+		Errors:    c.Errors(),
+		Value:     v,
+		Removed:   removed,
+		formatter: o.formatter,
+		locations: o.locations,
+	}
+}
+
+func (validator *Validator) options(opts []Option) *options {
+	all := make([]Option, 0, len(validator.opts)+len(opts))
+	all = append(all, validator.opts...)
+	all = append(all, opts...)
+	o := newOptions(all...)
+	o.refs = validator.refs
+	o.regexps = validator.regexps
+	return o
+}
+
+// root returns the constraint for the schema of the validator.
+func (validator *Validator) root(o *options) *baseConstraint {
+	c := NewBaseConstraint(validator.schema)
+	c.opts = o
+	c.base = schemaBase("", validator.schema)
+	return c
+}
+
+// Result is the outcome of validating one instance.
+type Result struct {
+	Errors []SchemaError
+	// Value is the validated instance, or its replacement in an interactive
+	// validation.
+	Value interface{}
+	// Removed holds the paths of the properties removed by WithRemoveAdditional.
+	Removed   []string
+	formatter ErrorFormatter
+	// positions holds the positions of the values of a YAML document by path.
+	positions map[string]Position
+	locations *keywordLocations
+}
+
+// Valid reports whether the instance had no errors.
+func (r *Result) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Messages returns one formatted message per error, prefixed with its path,
+// and its position for a YAML document. A message declared with the
+// errorMessage keyword takes precedence over the formatter.
+func (r *Result) Messages() []string {
+	messages := make([]string, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		msg := e.Path() + ": " + r.Format(e)
+		if pos, ok := r.Position(e); ok {
+			msg = pos.String() + ": " + msg
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// Position returns the position of the value of e in the YAML document
+// validated by Validator.ValidateYAML.
+func (r *Result) Position(e SchemaError) (Position, bool) {
+	pos, ok := r.positions[e.Path()]
+	return pos, ok
+}
+
+// KeywordLocation returns the JSON pointer of the keyword that raised e, along
+// the evaluation path from the root schema, such as /properties/a/$ref/maxLength.
+// It is empty for the errors not raised by a keyword, such as a canceled
+// validation.
+func (r *Result) KeywordLocation(e SchemaError) string {
+	return r.locations.get(e)
+}
+
+// Format returns the message of e, without its path.
+func (r *Result) Format(e SchemaError) string {
+	if msg := e.Message(); msg != "" {
+		return msg
+	}
+	return r.formatter.Format(e)
+}
@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorLocale(t *testing.T) {
+	assert.NoError(t, RegisterLocale("test-de", map[ErrorCode]string{
+		NumericMaximumError: "muss kleiner oder gleich {{.limit}} sein",
+	}))
+
+	v := NewValidator(Schema{
+		"maximum": json.Number("10"),
+	})
+
+	res := v.Validate(json.Number("5"))
+	assert.True(t, res.Valid())
+	assert.Empty(t, res.Messages())
+
+	res = v.Validate(json.Number("11"))
+	assert.False(t, res.Valid())
+	assert.Equal(t, []string{"$: must be less than or equal to 10"}, res.Messages())
+
+	res = v.Validate(json.Number("11"), WithLocale("test-de"))
+	assert.Equal(t, []string{"$: muss kleiner oder gleich 10 sein"}, res.Messages())
+
+	v = NewValidator(Schema{"maximum": json.Number("10")}, WithLocale("test-de"))
+	res = v.Validate(json.Number("11"), WithLocale("en"))
+	assert.Equal(t, []string{"$: must be less than or equal to 10"}, res.Messages())
+}
+
+func TestKeywordLocation(t *testing.T) {
+	s, err := deserializeSchema(`
+	{
+		"required": ["id"],
+		"properties": {
+			"name": {"$ref": "#/definitions/name"},
+			"tags": {"items": [{"type": "string"}], "additionalItems": {"maxLength": 1}}
+		},
+		"patternProperties": {
+			"^x-": {"type": "integer"}
+		},
+		"additionalProperties": false,
+		"definitions": {
+			"name": {"maxLength": 3}
+		}
+	}`)
+	assert.NoError(t, err)
+
+	doc := `{"name": "abcd", "tags": [1, "ab"], "x-a": "b", "y": 1}`
+	v, err := deserializeValue(doc)
+	assert.NoError(t, err)
+
+	expected := map[string]string{
+		"$":         "/required",
+		"$.name":    "/properties/name/$ref/maxLength",
+		"$.tags[0]": "/properties/tags/items/0/type",
+		"$.tags[1]": "/properties/tags/additionalItems/maxLength",
+		"$.x-a":     "/patternProperties/^x-/type",
+		"$.y":       "/additionalProperties",
+	}
+
+	validator := NewValidator(s)
+	res := validator.Validate(v)
+	actual := make(map[string]string)
+	for _, e := range res.Errors {
+		actual[e.Path()] = res.KeywordLocation(e)
+	}
+	assert.Equal(t, expected, actual)
+
+	res, err = validator.ValidateReader(strings.NewReader(doc))
+	assert.NoError(t, err)
+	actual = make(map[string]string)
+	for _, e := range res.Errors {
+		actual[e.Path()] = res.KeywordLocation(e)
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// schemaKeywords are the keywords whose value is a schema, arraySchemaKeywords
+// the ones whose value is an array of schemas, and mapSchemaKeywords the ones
+// whose value is an object of schemas.
+var (
+	schemaKeywords = []string{
+		"additionalItems", "additionalProperties", "contains", "else", "if",
+		"items", "not", "propertyNames", "then",
+	}
+	arraySchemaKeywords = []string{"allOf", "anyOf", "items", "oneOf"}
+	mapSchemaKeywords   = []string{
+		"$defs", "definitions", "dependencies", "patternProperties", "properties",
+	}
+)
+
+// Walk calls fn for s and every subschema of s, with the JSON pointer of the
+// subschema from s. The subschemas of a schema are visited after it, in a stable
+// order. If fn returns an error, Walk stops and returns it.
+func Walk(s Schema, fn func(pointer string, s Schema) error) error {
+	return walkScoped(s, "", "", func(pointer string, base string, s Schema) error {
+		return fn(pointer, s)
+	})
+}
+
+// walkScoped is Walk, also passing the base URI of each subschema, as changed
+// by the $id keywords on the way from s.
+func walkScoped(s Schema, pointer string, base string, fn func(pointer string, base string, s Schema) error) error {
+	base = schemaBase(base, s)
+	if err := fn(pointer, base, s); err != nil {
+		return err
+	}
+
+	for _, keyword := range schemaKeywords {
+		if sub, ok := s[keyword].(map[string]interface{}); ok {
+			if err := walkScoped(Schema(sub), pointer+"/"+keyword, base, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range arraySchemaKeywords {
+		subs, ok := s[keyword].([]interface{})
+		if !ok {
+			continue
+		}
+		for i, one := range subs {
+			if sub, ok := one.(map[string]interface{}); ok {
+				if err := walkScoped(Schema(sub), fmt.Sprintf("%s/%s/%d", pointer, keyword, i), base, fn); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, keyword := range mapSchemaKeywords {
+		subs, ok := s[keyword].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		names := make([]string, 0, len(subs))
+		for name := range subs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			// dependencies also holds arrays of property names
+			if sub, ok := subs[name].(map[string]interface{}); ok {
+				subPointer := pointer + "/" + keyword + "/" + escapePointer(name)
+				if err := walkScoped(Schema(sub), subPointer, base, fn); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// escapePointer escapes a JSON pointer reference token.
+func escapePointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// unescapePointer unescapes a JSON pointer reference token.
+func unescapePointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+}
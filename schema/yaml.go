@@ -0,0 +1,299 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxYAMLValues is the number of values a YAML document may expand to through
+// its aliases.
+const maxYAMLValues = 1000000
+
+// Position is a location in a YAML document.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// DecodeYAML decodes a YAML document to the values of encoding/json, with
+// json.Number numbers, and returns the position of every value by its path, as
+// in the errors of a validation. The position of a property is the one of its
+// key.
+//
+// The keys that are not strings, the timestamps, the binary values and the
+// custom tags are rejected, unless WithLenientYAML is given. The merge keys
+// (<<) are merged.
+func DecodeYAML(data []byte, opts ...Option) (interface{}, map[string]Position, error) {
+	return decodeYAML(data, newOptions(opts...).lenientYAML)
+}
+
+func decodeYAML(data []byte, lenient bool) (interface{}, map[string]Position, error) {
+	var doc yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&doc); err == io.EOF {
+		return nil, nil, errors.New("YAML Error: no document")
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("YAML Error: %s", err)
+	}
+	var next yaml.Node
+	if err := dec.Decode(&next); err == nil && len(next.Content) > 0 {
+		return nil, nil, fmt.Errorf("YAML Error: %s: unexpected document after the first one", nodePosition(next.Content[0]))
+	} else if err != nil && err != io.EOF {
+		return nil, nil, fmt.Errorf("YAML Error: %s", err)
+	}
+
+	d := &yamlDecoder{lenient: lenient, positions: make(map[string]Position)}
+	if len(doc.Content) == 0 {
+		return nil, d.positions, nil
+	}
+	v, err := d.value(doc.Content[0], rootPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, d.positions, nil
+}
+
+// CompileYAML compiles the schema of a YAML document, see DecodeYAML and
+// Compile.
+func CompileYAML(data []byte, opts ...Option) (*Validator, error) {
+	v, _, err := DecodeYAML(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("YAML Error: the schema is not an object")
+	}
+	return Compile(Schema(s), opts...)
+}
+
+// ValidateYAML validates a YAML document, see DecodeYAML. The positions of the
+// errors in the document are given by Result.Position.
+//
+// The returned error is only set if the document is not valid YAML, or can not
+// be converted to JSON values.
+func (validator *Validator) ValidateYAML(data []byte, opts ...Option) (*Result, error) {
+	v, positions, err := decodeYAML(data, validator.options(opts).lenientYAML)
+	if err != nil {
+		return nil, err
+	}
+	res := validator.Validate(v, opts...)
+	res.positions = positions
+	return res, nil
+}
+
+type yamlDecoder struct {
+	lenient   bool
+	positions map[string]Position
+	// values counts the values decoded, the ones of the aliases included.
+	values int
+	// aliases are the anchors being expanded, to detect the recursive ones.
+	aliases map[*yaml.Node]bool
+}
+
+func nodePosition(n *yaml.Node) Position {
+	return Position{Line: n.Line, Column: n.Column}
+}
+
+func (d *yamlDecoder) errorf(n *yaml.Node, format string, args ...interface{}) error {
+	return fmt.Errorf("YAML Error: %s: %s", nodePosition(n), fmt.Sprintf(format, args...))
+}
+
+// value decodes the value of n found at path.
+func (d *yamlDecoder) value(n *yaml.Node, path string) (interface{}, error) {
+	if d.values++; d.values > maxYAMLValues {
+		return nil, d.errorf(n, "the document expands to more than %d values", maxYAMLValues)
+	}
+	if _, ok := d.positions[path]; !ok {
+		d.positions[path] = nodePosition(n)
+	}
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		if d.aliases[n.Alias] {
+			return nil, d.errorf(n, "the alias %s is recursive", n.Value)
+		}
+		if d.aliases == nil {
+			d.aliases = make(map[*yaml.Node]bool)
+		}
+		d.aliases[n.Alias] = true
+		defer delete(d.aliases, n.Alias)
+		return d.value(n.Alias, path)
+	case yaml.SequenceNode:
+		if err := d.checkTag(n, "!!seq"); err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, len(n.Content))
+		for i, item := range n.Content {
+			v, err := d.value(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+	case yaml.MappingNode:
+		if err := d.checkTag(n, "!!map"); err != nil {
+			return nil, err
+		}
+		return d.mapping(n, path)
+	}
+	return d.scalar(n)
+}
+
+// checkTag rejects the custom tags of n, unless lenient.
+func (d *yamlDecoder) checkTag(n *yaml.Node, tag string) error {
+	if n.ShortTag() != tag && !d.lenient {
+		return d.errorf(n, "the tag %s is not supported", n.Tag)
+	}
+	return nil
+}
+
+func (d *yamlDecoder) mapping(n *yaml.Node, path string) (interface{}, error) {
+	m := make(map[string]interface{}, len(n.Content)/2)
+	var merges []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			merges = append(merges, value)
+			continue
+		}
+
+		prop, err := d.key(key)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m[prop]; ok {
+			return nil, d.errorf(key, "the key %s is defined twice", prop)
+		}
+		subPath := fmt.Sprintf("%s.%s", path, prop)
+		d.positions[subPath] = nodePosition(key)
+		if m[prop], err = d.value(value, subPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// the keys of the mapping take precedence over the merged ones, and the
+	// first merged mappings over the next ones
+	for _, merge := range merges {
+		sources := []*yaml.Node{merge}
+		if resolveAlias(merge).Kind == yaml.SequenceNode {
+			sources = resolveAlias(merge).Content
+		}
+		for _, source := range sources {
+			sub := &yamlDecoder{lenient: d.lenient, positions: make(map[string]Position), values: d.values, aliases: d.aliases}
+			v, err := sub.value(source, path)
+			if err != nil {
+				return nil, err
+			}
+			d.values = sub.values
+			merged, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, d.errorf(source, "the value merged by << is not a mapping")
+			}
+			for prop, value := range merged {
+				if _, ok := m[prop]; ok {
+					continue
+				}
+				m[prop] = value
+				subPath := fmt.Sprintf("%s.%s", path, prop)
+				for p, pos := range sub.positions {
+					if p == subPath || strings.HasPrefix(p, subPath+".") || strings.HasPrefix(p, subPath+"[") {
+						d.positions[p] = pos
+					}
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// key returns the property name of the key n.
+func (d *yamlDecoder) key(n *yaml.Node) (string, error) {
+	n = resolveAlias(n)
+	if n.Kind != yaml.ScalarNode {
+		return "", d.errorf(n, "the keys must be strings")
+	}
+	if n.ShortTag() != "!!str" && !d.lenient {
+		return "", d.errorf(n, "the key %s is not a string, quote it", n.Value)
+	}
+	return n.Value, nil
+}
+
+// scalar decodes the scalar n as a JSON value.
+func (d *yamlDecoder) scalar(n *yaml.Node) (interface{}, error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return nil, d.errorf(n, "%s", err)
+		}
+		return b, nil
+	case "!!int", "!!float":
+		return d.number(n)
+	case "!!str":
+		return n.Value, nil
+	case "!!timestamp":
+		if !d.lenient {
+			return nil, d.errorf(n, "the timestamp %s is not a JSON value, quote it", n.Value)
+		}
+		return n.Value, nil
+	case "!!binary":
+		if !d.lenient {
+			return nil, d.errorf(n, "binary values are not JSON values")
+		}
+		return n.Value, nil
+	}
+	if !d.lenient {
+		return nil, d.errorf(n, "the tag %s is not supported", n.Tag)
+	}
+	return n.Value, nil
+}
+
+// number decodes the number n as a json.Number, keeping its text if it is
+// written as in JSON.
+func (d *yamlDecoder) number(n *yaml.Node) (interface{}, error) {
+	if numberRegexp.MatchString(n.Value) {
+		return json.Number(n.Value), nil
+	}
+
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return nil, d.errorf(n, "%s", err)
+	}
+	switch v := v.(type) {
+	case int:
+		return json.Number(strconv.Itoa(v)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10)), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, d.errorf(n, "%s is not a JSON number", n.Value)
+		}
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64)), nil
+	}
+	return nil, d.errorf(n, "%s is not a JSON number", n.Value)
+}
@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		yaml    string
+		lenient bool
+		value   string
+		err     string
+	}{
+		{
+			yaml:  "a: 1\nb: [true, null, 1.50, x, \"2\"]\nc: 0x1F\nd: 1e3\ne: -.5\nf: 123456789012345678901234567890\n",
+			value: `{"a": 1, "b": [true, null, 1.50, "x", "2"], "c": 31, "d": 1e3, "e": -0.5, "f": 123456789012345678901234567890}`,
+		},
+		{
+			yaml:  "base: &base {a: 1, b: [2]}\nitem:\n  <<: *base\n  a: 3\nlist: *base\n",
+			value: `{"base": {"a": 1, "b": [2]}, "item": {"a": 3, "b": [2]}, "list": {"a": 1, "b": [2]}}`,
+		},
+		{yaml: "- a\n- ~\n", value: `["a", null]`},
+		{yaml: "", err: "YAML Error: no document"},
+		{yaml: "1: a\n", err: "YAML Error: 1:1: the key 1 is not a string, quote it"},
+		{yaml: "[a]: 1\n", err: "YAML Error: 1:1: the keys must be strings"},
+		{yaml: "at: 2001-12-14\n", err: "YAML Error: 1:5: the timestamp 2001-12-14 is not a JSON value, quote it"},
+		{yaml: "a: !!binary aGVsbG8=\n", err: "YAML Error: 1:4: binary values are not JSON values"},
+		{yaml: "a: !Ref b\n", err: "YAML Error: 1:4: the tag !Ref is not supported"},
+		{yaml: "a:\n  - .inf\n", err: "YAML Error: 2:5: .inf is not a JSON number"},
+		{yaml: "a: 1\nb: 2\na: 3\n", err: "YAML Error: 3:1: the key a is defined twice"},
+		{yaml: "a: &a [*a]\n", err: "YAML Error: 1:8: the alias a is recursive"},
+		{yaml: "a: 1\n---\nb: 2\n", err: "YAML Error: 3:1: unexpected document after the first one"},
+		{yaml: "a: [\n", err: "YAML Error: yaml: line 1: did not find expected node content"},
+		{yaml: "a: 1\n---\nb: [\n", err: "YAML Error: yaml: line 3: did not find expected node content"},
+		{yaml: "1: a\ntrue: b\nat: 2001-12-14\nbin: !!binary aGVsbG8=\nref: !Ref c\n", lenient: true, value: `{"1": "a", "true": "b", "at": "2001-12-14", "bin": "aGVsbG8=", "ref": "c"}`},
+	}
+
+	for _, test := range tests {
+		var opts []Option
+		if test.lenient {
+			opts = append(opts, WithLenientYAML())
+		}
+		v, _, err := DecodeYAML([]byte(test.yaml), opts...)
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, test.yaml)
+			continue
+		}
+		if assert.NoError(t, err, test.yaml) {
+			var expected interface{}
+			dec := json.NewDecoder(strings.NewReader(test.value))
+			dec.UseNumber()
+			assert.NoError(t, dec.Decode(&expected))
+			assert.Equal(t, expected, v, test.yaml)
+		}
+	}
+}
+
+func TestValidateYAML(t *testing.T) {
+	validator, err := CompileYAML([]byte(`
+type: object
+properties:
+  kind: {enum: [Deployment]}
+  spec:
+    type: object
+    properties:
+      replicas: {type: integer, minimum: 1}
+      ports:
+        type: array
+        items: {type: integer, maximum: 65535}
+    required: [selector]
+required: [kind, spec]
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	res, err := validator.ValidateYAML([]byte("kind: Deployment\nspec:\n  replicas: 3\n  selector: {}\n  ports: [80, 443]\n"))
+	assert.NoError(t, err)
+	assert.True(t, res.Valid())
+
+	res, err = validator.ValidateYAML([]byte(`kind: Deployment
+defaults: &defaults
+  replicas: 0
+spec:
+  <<: *defaults
+  ports:
+    - 80
+    - 70000
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"4:1: $.spec: is missing the required property selector",
+		"8:7: $.spec.ports[1]: must be less than or equal to 65535",
+		"3:3: $.spec.replicas: must be greater than or equal to 1",
+	}, res.Messages())
+	pos, ok := res.Position(res.Errors[2])
+	assert.True(t, ok)
+	assert.Equal(t, Position{Line: 3, Column: 3}, pos)
+
+	_, err = validator.ValidateYAML([]byte("kind: Deployment\nspec: {created: 2001-12-14}\n"))
+	assert.EqualError(t, err, "YAML Error: 2:17: the timestamp 2001-12-14 is not a JSON value, quote it")
+	res, err = validator.ValidateYAML([]byte("kind: Deployment\nspec: {created: 2001-12-14, selector: {}}\n"), WithLenientYAML())
+	assert.NoError(t, err)
+	assert.True(t, res.Valid())
+
+	_, err = CompileYAML([]byte("- type: string\n"))
+	assert.EqualError(t, err, "YAML Error: the schema is not an object")
+}
+
+func TestLoadYAML(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "name.yaml"), []byte("type: string\nmaxLength: 3\n"), 0644))
+
+	validator, err := Compile(Schema{"properties": map[string]interface{}{
+		"name": map[string]interface{}{"$ref": "file://" + filepath.ToSlash(filepath.Join(dir, "name.yaml"))},
+	}}, WithFileRefs())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, validator.Validate(map[string]interface{}{"name": "abc"}).Valid())
+	assert.False(t, validator.Validate(map[string]interface{}{"name": "abcd"}).Valid())
+}
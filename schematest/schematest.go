@@ -0,0 +1,193 @@
+// Package schematest tests functions with random values generated from a
+// schema, see schema.Generate, shrinking the values they fail for.
+package schematest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/csimplestring/go-json-schema/schema"
+)
+
+// DefaultCount is the number of values Check tests by default.
+const DefaultCount = 100
+
+// maxShrinks is the number of smaller values tried at most when shrinking a
+// failing value.
+const maxShrinks = 1000
+
+// Option configures Check and Fuzz.
+type Option func(*config)
+
+type config struct {
+	count      int
+	seed       int64
+	invalid    bool
+	schemaOpts []schema.Option
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{count: DefaultCount}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.seed == 0 {
+		c.seed = time.Now().UnixNano()
+	}
+	return c
+}
+
+// WithCount tests n values, or adds n seeds to the corpus of Fuzz.
+func WithCount(n int) Option {
+	return func(c *config) {
+		c.count = n
+	}
+}
+
+// WithSeed generates the values from seed instead of the current time, to
+// replay the values of a failed test.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// WithInvalid tests values the schema rejects for a single keyword, see
+// schema.GenerateInvalid, to exercise the error paths.
+func WithInvalid() Option {
+	return func(c *config) {
+		c.invalid = true
+	}
+}
+
+// WithSchemaOptions generates and validates the values with opts, for
+// example to load the schemas of the $refs.
+func WithSchemaOptions(opts ...schema.Option) Option {
+	return func(c *config) {
+		c.schemaOpts = append(c.schemaOpts, opts...)
+	}
+}
+
+// Check calls f with random values valid against s. When f returns an error or
+// panics for a value, the value is shrunk to a smallest one that is still
+// valid and still fails, and reported with t.Errorf.
+func Check(t testing.TB, s schema.Schema, f func(v interface{}) error, opts ...Option) {
+	t.Helper()
+	c := newConfig(opts...)
+	ch, err := newChecker(s, f, c)
+	if err != nil {
+		t.Fatalf("schematest: %s", err)
+	}
+
+	src := rand.NewSource(c.seed)
+	for i := 0; i < c.count; i++ {
+		if !ch.check(t, src, fmt.Sprintf("value %d of the seed %d", i+1, c.seed)) {
+			return
+		}
+	}
+}
+
+// Fuzz adds seeds to the corpus of f and fuzzes fn with the values generated
+// from them, as Check does.
+func Fuzz(f *testing.F, s schema.Schema, fn func(v interface{}) error, opts ...Option) {
+	f.Helper()
+	c := newConfig(opts...)
+	ch, err := newChecker(s, fn, c)
+	if err != nil {
+		f.Fatalf("schematest: %s", err)
+	}
+
+	for i := 0; i < c.count; i++ {
+		f.Add(c.seed + int64(i))
+	}
+	f.Fuzz(func(t *testing.T, seed int64) {
+		ch.check(t, rand.NewSource(seed), fmt.Sprintf("value of the seed %d", seed))
+	})
+}
+
+type checker struct {
+	schema    schema.Schema
+	validator *schema.Validator
+	f         func(v interface{}) error
+	config    *config
+}
+
+func newChecker(s schema.Schema, f func(v interface{}) error, c *config) (*checker, error) {
+	validator, err := schema.Compile(s, c.schemaOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &checker{schema: s, validator: validator, f: f, config: c}, nil
+}
+
+// check tests a value generated from src, and reports it as name if it fails.
+// It returns false if it failed.
+func (ch *checker) check(t testing.TB, src rand.Source, name string) bool {
+	t.Helper()
+	generate := schema.Generate
+	if ch.config.invalid {
+		generate = schema.GenerateInvalid
+	}
+	v, err := generate(ch.schema, src, ch.config.schemaOpts...)
+	if err != nil {
+		t.Fatalf("schematest: %s", err)
+		return false
+	}
+
+	err = ch.call(v)
+	if err == nil {
+		return true
+	}
+	shrunk, shrunkErr := ch.shrink(v, err)
+	t.Errorf("schematest: %s fails: %s\nshrunk from the %s: %s", jsonString(shrunk), shrunkErr, name, jsonString(v))
+	return false
+}
+
+// call calls f with a copy of v, turning its panics into errors.
+func (ch *checker) call(v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return ch.f(clone(v))
+}
+
+// fails returns the error of f for v, if v is valid, or invalid with
+// WithInvalid.
+func (ch *checker) fails(v interface{}) error {
+	if ch.validator.Validate(v).Valid() == ch.config.invalid {
+		return nil
+	}
+	return ch.call(v)
+}
+
+// shrink returns the smallest value found from v that still fails, with its
+// error.
+func (ch *checker) shrink(v interface{}, err error) (interface{}, error) {
+	tries := 0
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for _, candidate := range shrinks(v) {
+			if tries++; tries > maxShrinks {
+				return v, err
+			}
+			if candidateErr := ch.fails(candidate); candidateErr != nil {
+				v, err, shrunk = candidate, candidateErr, true
+				break
+			}
+		}
+	}
+	return v, err
+}
+
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,125 @@
+package schematest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/csimplestring/go-json-schema/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the failures of a test instead of failing it.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func deserialize(t testing.TB, str string) schema.Schema {
+	var s schema.Schema
+	dec := json.NewDecoder(strings.NewReader(str))
+	dec.UseNumber()
+	assert.NoError(t, dec.Decode(&s))
+	return s
+}
+
+func TestCheck(t *testing.T) {
+	s := deserialize(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"scores": {"type": "array", "items": {"type": "integer", "minimum": 0, "maximum": 100}, "minItems": 1}
+		},
+		"required": ["name", "scores"]
+	}`)
+
+	var count int
+	Check(t, s, func(v interface{}) error {
+		count++
+		object := v.(map[string]interface{})
+		if len(object["name"].(string)) == 0 {
+			return errors.New("no name")
+		}
+		return nil
+	}, WithCount(50), WithSeed(1))
+	assert.Equal(t, 50, count)
+
+	// the failing value is shrunk to the smallest valid one still failing
+	r := &recorder{TB: t}
+	Check(r, s, func(v interface{}) error {
+		for _, score := range v.(map[string]interface{})["scores"].([]interface{}) {
+			if n, _ := score.(json.Number).Int64(); n >= 10 {
+				return fmt.Errorf("score %d is too high", n)
+			}
+		}
+		return nil
+	}, WithSeed(1))
+	if assert.Len(t, r.errors, 1) {
+		assert.Regexp(t, `^schematest: {"name":".","scores":\[10\]} fails: score 10 is too high\nshrunk from the value \d+ of the seed 1: {.*}$`, r.errors[0])
+	}
+
+	// the panics are failures, and the values given to f are copies
+	r = &recorder{TB: t}
+	Check(r, s, func(v interface{}) error {
+		object := v.(map[string]interface{})
+		delete(object, "name")
+		if len(object["scores"].([]interface{})) > 1 {
+			panic("too many scores")
+		}
+		return nil
+	}, WithSeed(1))
+	if assert.Len(t, r.errors, 1) {
+		assert.Regexp(t, `^schematest: {"name":".","scores":\[0,0\]} fails: panic: too many scores\n`, r.errors[0])
+	}
+
+	r = &recorder{TB: t}
+	Check(r, deserialize(t, `{"type": "string", "pattern": "\\bword"}`), func(v interface{}) error { return nil })
+	assert.Equal(t, []string{`schematest: Generate Error: pattern \bword at #: \b is not supported`}, r.errors)
+}
+
+func TestCheckInvalid(t *testing.T) {
+	s := deserialize(t, `{"type": "object", "properties": {"age": {"type": "integer", "minimum": 0}}, "required": ["age"]}`)
+	validator := schema.NewValidator(s)
+
+	Check(t, s, func(v interface{}) error {
+		if validator.Validate(v).Valid() {
+			return errors.New("valid")
+		}
+		return nil
+	}, WithInvalid(), WithSeed(1))
+
+	// the shrunk values stay invalid
+	r := &recorder{TB: t}
+	Check(r, s, func(v interface{}) error {
+		return errors.New("invalid")
+	}, WithInvalid(), WithSeed(1))
+	if assert.Len(t, r.errors, 1) {
+		assert.Equal(t, `schematest: {} fails: invalid
+shrunk from the value 1 of the seed 1: {"age":{}}`, r.errors[0])
+	}
+}
+
+func FuzzCheck(f *testing.F) {
+	s := deserialize(f, `{"type": "array", "items": {"type": "string", "maxLength": 3}, "maxItems": 4}`)
+
+	Fuzz(f, s, func(v interface{}) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if len(b) > len(`["xxx","xxx","xxx","xxx"]`)*4 {
+			return fmt.Errorf("%s is too long", b)
+		}
+		return nil
+	}, WithCount(10), WithSeed(1))
+}
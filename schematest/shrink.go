@@ -0,0 +1,132 @@
+package schematest
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// shrinks returns the values smaller than v by one step, the greatest steps
+// first: the removed properties and items, then the smaller values of each.
+func shrinks(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var candidates []interface{}
+		for _, key := range keys {
+			m := clone(v).(map[string]interface{})
+			delete(m, key)
+			candidates = append(candidates, m)
+		}
+		for _, key := range keys {
+			for _, smaller := range shrinks(v[key]) {
+				m := clone(v).(map[string]interface{})
+				m[key] = smaller
+				candidates = append(candidates, m)
+			}
+		}
+		return candidates
+	case []interface{}:
+		var candidates []interface{}
+		if len(v) > 1 {
+			candidates = append(candidates, clone(v[:len(v)/2]))
+		}
+		for i := range v {
+			a := clone(v).([]interface{})
+			candidates = append(candidates, append(a[:i], a[i+1:]...))
+		}
+		for i := range v {
+			for _, smaller := range shrinks(v[i]) {
+				a := clone(v).([]interface{})
+				a[i] = smaller
+				candidates = append(candidates, a)
+			}
+		}
+		return candidates
+	case string:
+		runes := []rune(v)
+		if len(runes) == 0 {
+			return nil
+		}
+		candidates := []interface{}{""}
+		if len(runes) > 1 {
+			candidates = append(candidates, string(runes[:len(runes)/2]))
+		}
+		for i := range runes {
+			candidates = append(candidates, string(runes[:i])+string(runes[i+1:]))
+		}
+		return candidates
+	case json.Number:
+		return shrinkNumber(v)
+	case bool:
+		if v {
+			return []interface{}{false}
+		}
+	}
+	return nil
+}
+
+// shrinkNumber returns the numbers closer to 0 than n: 0, its integer part, its
+// half, and the integers next to it.
+func shrinkNumber(n json.Number) []interface{} {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		if i == 0 {
+			return nil
+		}
+		candidates := []interface{}{json.Number("0")}
+		if i < 0 {
+			candidates = append(candidates, json.Number(strconv.FormatInt(-i, 10)))
+		}
+		for _, smaller := range []int64{i / 2, i - sign(i)} {
+			if smaller != 0 {
+				candidates = append(candidates, json.Number(strconv.FormatInt(smaller, 10)))
+			}
+		}
+		return candidates
+	}
+
+	f, err := n.Float64()
+	if err != nil || f == 0 {
+		return nil
+	}
+	candidates := []interface{}{json.Number("0")}
+	if f < 0 {
+		candidates = append(candidates, json.Number(strconv.FormatFloat(-f, 'g', -1, 64)))
+	}
+	if trunc := math.Trunc(f); trunc != f && math.Abs(trunc) < math.MaxInt64 {
+		candidates = append(candidates, json.Number(strconv.FormatInt(int64(trunc), 10)))
+	}
+	return append(candidates, json.Number(strconv.FormatFloat(f/2, 'g', -1, 64)))
+}
+
+func sign(i int64) int64 {
+	if i < 0 {
+		return -1
+	}
+	return 1
+}
+
+// clone returns a deep copy of the JSON value v.
+func clone(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = clone(value)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, value := range v {
+			a[i] = clone(value)
+		}
+		return a
+	}
+	return v
+}